@@ -0,0 +1,261 @@
+package jusibe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitCoolDown         = (time.Second * 30)
+	defaultCircuitHalfOpenMaxCalls = 1
+	defaultCircuitSuccessThreshold = 1
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails every call with ErrCircuitOpen until the cool-down elapses
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of probe calls through to find out whether Jusibe recovered
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker configures a circuit breaker shared by every call of the client
+// The circuit opens after FailureThreshold consecutive failures, and fails calls with ErrCircuitOpen
+// without making any http request. After CoolDown it lets HalfOpenMaxCalls probe calls through: SuccessThreshold
+// successful probes close the circuit, while a single failed probe opens it again for another CoolDown
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures which opens the circuit. Defaults to 5
+	FailureThreshold int
+
+	// CoolDown is how long the circuit stays open before letting probe calls through. Defaults to 30s
+	CoolDown time.Duration
+
+	// HalfOpenMaxCalls is the number of concurrent probe calls allowed while half-open. Defaults to 1
+	HalfOpenMaxCalls int
+
+	// SuccessThreshold is the number of successful probe calls which closes the circuit. Defaults to 1
+	SuccessThreshold int
+
+	// IsFailure reports whether the error of a call counts as a failure. By default network errors, timeouts
+	// and 5xx http response codes are failures, while other http response codes show that Jusibe is up
+	// Calls canceled by their caller are never counted
+	IsFailure func(err error) bool
+
+	// OnStateChange is called after every state change, from the goroutine making the call which triggered it
+	OnStateChange func(from, to CircuitState)
+}
+
+func (cb *CircuitBreaker) validate() (err error) {
+	if cb.FailureThreshold < 0 {
+		err = errors.New("invalid CircuitBreaker: FailureThreshold must not be negative")
+	} else if cb.CoolDown < 0 {
+		err = errors.New("invalid CircuitBreaker: CoolDown must not be negative")
+	} else if cb.HalfOpenMaxCalls < 0 {
+		err = errors.New("invalid CircuitBreaker: HalfOpenMaxCalls must not be negative")
+	} else if cb.SuccessThreshold < 0 {
+		err = errors.New("invalid CircuitBreaker: SuccessThreshold must not be negative")
+	}
+	return
+}
+
+// isCircuitFailure is the default CircuitBreaker.IsFailure
+func isCircuitFailure(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return true
+}
+
+// circuitBreaker is the state machine configured by a CircuitBreaker
+type circuitBreaker struct {
+	failureThreshold int
+	coolDown         time.Duration
+	halfOpenMaxCalls int
+	successThreshold int
+	isFailure        func(err error) bool
+	onStateChange    func(from, to CircuitState)
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+
+	// generation changes with every state change, so that calls let through in a previous state are ignored
+	generation uint64
+
+	now func() time.Time
+}
+
+// newCircuitBreaker creates a closed circuit breaker
+func newCircuitBreaker(cb *CircuitBreaker) *circuitBreaker {
+	b := &circuitBreaker{
+		failureThreshold: cb.FailureThreshold,
+		coolDown:         cb.CoolDown,
+		halfOpenMaxCalls: cb.HalfOpenMaxCalls,
+		successThreshold: cb.SuccessThreshold,
+		isFailure:        cb.IsFailure,
+		onStateChange:    cb.OnStateChange,
+		now:              time.Now,
+	}
+	if b.failureThreshold == 0 {
+		b.failureThreshold = defaultCircuitFailureThreshold
+	}
+	if b.coolDown == 0 {
+		b.coolDown = defaultCircuitCoolDown
+	}
+	if b.halfOpenMaxCalls == 0 {
+		b.halfOpenMaxCalls = defaultCircuitHalfOpenMaxCalls
+	}
+	if b.successThreshold == 0 {
+		b.successThreshold = defaultCircuitSuccessThreshold
+	}
+	if b.isFailure == nil {
+		b.isFailure = isCircuitFailure
+	}
+	return b
+}
+
+// currentState returns the state of the circuit
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// setState moves the circuit to state and returns the state it left
+// It must be called with mu held
+func (b *circuitBreaker) setState(state CircuitState) (from CircuitState) {
+	from = b.state
+	b.state = state
+	b.failures, b.successes, b.probes = 0, 0, 0
+	b.generation++
+	if state == CircuitOpen {
+		b.openedAt = b.now()
+	}
+	return
+}
+
+// notify calls OnStateChange, if the state changed
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.onStateChange != nil {
+		b.onStateChange(from, to)
+	}
+}
+
+// allow reports whether a call may proceed, and the generation to record its outcome against
+func (b *circuitBreaker) allow() (generation uint64, err error) {
+	b.mu.Lock()
+
+	from, to := b.state, b.state
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.coolDown {
+		b.setState(CircuitHalfOpen)
+		to = CircuitHalfOpen
+	}
+
+	switch {
+	case b.state == CircuitOpen:
+		err = ErrCircuitOpen
+	case b.state == CircuitHalfOpen && b.probes >= b.halfOpenMaxCalls:
+		err = ErrCircuitOpen
+	case b.state == CircuitHalfOpen:
+		b.probes++
+	}
+	generation = b.generation
+
+	b.mu.Unlock()
+	b.notify(from, to)
+
+	return
+}
+
+// record updates the circuit with the outcome of a call let through by allow
+func (b *circuitBreaker) record(generation uint64, err error) {
+	b.mu.Lock()
+
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	from, to := b.state, b.state
+	canceled := errors.Is(err, context.Canceled)
+	failed := err != nil && !canceled && b.isFailure(err)
+
+	switch b.state {
+	case CircuitClosed:
+		if failed {
+			b.failures++
+			if b.failures >= b.failureThreshold {
+				b.setState(CircuitOpen)
+			}
+		} else if !canceled {
+			b.failures = 0
+		}
+	case CircuitHalfOpen:
+		b.probes--
+		if failed {
+			b.setState(CircuitOpen)
+		} else if !canceled {
+			b.successes++
+			if b.successes >= b.successThreshold {
+				b.setState(CircuitClosed)
+			}
+		}
+	}
+	to = b.state
+
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// middleware fails calls with ErrCircuitOpen while the circuit is open
+func (b *circuitBreaker) middleware() Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call *Call) error {
+			generation, err := b.allow()
+			if err != nil {
+				return err
+			}
+
+			err = next(ctx, call)
+			b.record(generation, err)
+
+			return err
+		}
+	}
+}
+
+// CircuitState returns the state of the circuit breaker configured by Config.CircuitBreaker
+// It is always CircuitClosed when no circuit breaker is configured
+func (j *Jusibe) CircuitState() CircuitState {
+	if j.circuitBreaker == nil {
+		return CircuitClosed
+	}
+	return j.circuitBreaker.currentState()
+}
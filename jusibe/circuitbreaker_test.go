@@ -0,0 +1,148 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("New should validate CircuitBreaker", func(t *testing.T) {
+		_, err := New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key", CircuitBreaker: &CircuitBreaker{FailureThreshold: -1}})
+		assert.Error(t, err)
+
+		_, err = New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key", CircuitBreaker: &CircuitBreaker{CoolDown: -time.Second}})
+		assert.Error(t, err)
+	})
+
+	t.Run("circuit should open, fail fast, probe and close", func(t *testing.T) {
+		var transitions []string
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			CircuitBreaker: &CircuitBreaker{
+				FailureThreshold: 2,
+				CoolDown:         time.Minute,
+				OnStateChange: func(from, to CircuitState) {
+					transitions = append(transitions, from.String()+" -> "+to.String())
+				},
+			},
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		jusibe.circuitBreaker.now = func() time.Time { return now }
+
+		respond := func(statusCode int, body string) func(req *http.Request) (*http.Response, error) {
+			return func(req *http.Request) (*http.Response, error) {
+				res := &http.Response{StatusCode: statusCode}
+				res.Body = ioutil.NopCloser(bytes.NewReader([]byte(body)))
+				return res, nil
+			}
+		}
+
+		gomock.InOrder(
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).DoAndReturn(respond(503, `{"error": "Service Unavailable"}`)),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).DoAndReturn(respond(401, `{"error": "Invalid credentials"}`)),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(nil, errors.New("connection refused")),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).DoAndReturn(respond(503, `{"error": "Service Unavailable"}`)),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(nil, errors.New("connection refused")),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).DoAndReturn(respond(200, `{"sms_credits": "10"}`)),
+		)
+
+		ctx := context.Background()
+
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.True(t, errors.Is(err, ErrServer))
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.True(t, errors.Is(err, ErrUnauthorized))
+		assert.Equal(t, CircuitClosed, jusibe.CircuitState(), "4xx responses should reset consecutive failures")
+
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.Error(t, err)
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.True(t, errors.Is(err, ErrServer))
+		assert.Equal(t, CircuitOpen, jusibe.CircuitState())
+
+		_, res, err := jusibe.SendSMS(ctx, "08031234567", "test_user", "Hello World!")
+		assert.Equal(t, ErrCircuitOpen, err)
+		assert.Nil(t, res)
+
+		now = now.Add(time.Minute)
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrCircuitOpen, err)
+		assert.Equal(t, CircuitOpen, jusibe.CircuitState(), "a failed probe should open the circuit again")
+
+		now = now.Add(30 * time.Second)
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.Equal(t, ErrCircuitOpen, err, "the cool-down should restart")
+
+		now = now.Add(30 * time.Second)
+		scr, _, err := jusibe.CheckSMSCredits(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "10", scr.SMSCredits)
+		assert.Equal(t, CircuitClosed, jusibe.CircuitState())
+
+		assert.Equal(t, []string{
+			"closed -> open",
+			"open -> half-open",
+			"half-open -> open",
+			"open -> half-open",
+			"half-open -> closed",
+		}, transitions)
+	})
+
+	t.Run("half-open circuit should limit probes and ignore stale calls", func(t *testing.T) {
+		b := newCircuitBreaker(&CircuitBreaker{FailureThreshold: 1, SuccessThreshold: 2})
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		b.now = func() time.Time { return now }
+
+		stale, err := b.allow()
+		assert.NoError(t, err)
+
+		generation, err := b.allow()
+		assert.NoError(t, err)
+		b.record(generation, errors.New("timeout"))
+		assert.Equal(t, CircuitOpen, b.currentState())
+
+		b.record(stale, nil)
+		assert.Equal(t, CircuitOpen, b.currentState(), "calls let through while closed should be ignored")
+
+		now = now.Add(defaultCircuitCoolDown)
+		probe, err := b.allow()
+		assert.NoError(t, err)
+		_, err = b.allow()
+		assert.Equal(t, ErrCircuitOpen, err, "only one probe should be let through")
+
+		b.record(probe, context.Canceled)
+		assert.Equal(t, CircuitHalfOpen, b.currentState(), "canceled calls should not count")
+
+		for i := 0; i < 2; i++ {
+			probe, err = b.allow()
+			assert.NoError(t, err)
+			b.record(probe, nil)
+		}
+		assert.Equal(t, CircuitClosed, b.currentState())
+	})
+
+	t.Run("CircuitState should be closed without a circuit breaker", func(t *testing.T) {
+		jusibe, err := New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key"})
+		assert.NoError(t, err)
+		assert.Equal(t, CircuitClosed, jusibe.CircuitState())
+		assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	})
+}
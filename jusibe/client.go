@@ -0,0 +1,18 @@
+package jusibe
+
+import (
+	"context"
+	"net/http"
+)
+
+// Client is the set of Jusibe API operations implemented by *Jusibe
+// Depend on Client rather than *Jusibe so that tests can swap in a fake such as jusibefake.Fake
+type Client interface {
+	SendSMS(ctx context.Context, to, from, message string) (*SMSResponse, *http.Response, error)
+	SendBulkSMS(ctx context.Context, to, from, message string) (*BulkSMSResponse, *http.Response, error)
+	CheckSMSCredits(ctx context.Context) (*SMSCreditsResponse, *http.Response, error)
+	CheckSMSDeliveryStatus(ctx context.Context, messageID string) (*SMSDeliveryResponse, *http.Response, error)
+	CheckBulkSMSStatus(ctx context.Context, messageID string) (*BulkSMSStatusResponse, *http.Response, error)
+}
+
+var _ Client = (*Jusibe)(nil)
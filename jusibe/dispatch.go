@@ -0,0 +1,226 @@
+package jusibe
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultDispatchChunkSize   = 100
+	defaultDispatchConcurrency = 4
+)
+
+// DispatchOptions configures how DispatchBulk splits and submits recipients
+// A nil *DispatchOptions uses the default values
+type DispatchOptions struct {
+	// ChunkSize is the maximum number of recipients submitted in a single Bulk SMS request. Defaults to 100
+	ChunkSize int
+
+	// Concurrency is the maximum number of chunks submitted at the same time. Defaults to 4
+	Concurrency int
+
+	// NormalizeRecipient, when set, is applied to every recipient before deduplication, so that different forms of
+	// the same phone number are only sent once. Jusibe.DispatchBulk defaults it to Config.NormalizeRecipient
+	NormalizeRecipient func(to string) (string, error)
+
+	// OnChunk is called after every chunk is submitted
+	// It is called from the submitting goroutines, so it must be safe for concurrent use
+	OnChunk func(BulkChunkResult)
+}
+
+func (o *DispatchOptions) withDefaults() *DispatchOptions {
+	cp := DispatchOptions{}
+	if o != nil {
+		cp = *o
+	}
+	if cp.ChunkSize <= 0 {
+		cp.ChunkSize = defaultDispatchChunkSize
+	}
+	if cp.Concurrency <= 0 {
+		cp.Concurrency = defaultDispatchConcurrency
+	}
+	return &cp
+}
+
+// RecipientResult is the outcome of sending to a single recipient
+type RecipientResult struct {
+	// BulkMessageID is the id of the Bulk SMS the recipient was submitted in. It is empty when Err is set
+	BulkMessageID string
+
+	// Err is the validation error of the recipient, or the error of the chunk it was submitted in
+	Err error
+}
+
+// BulkChunkResult is the outcome of a single Bulk SMS request
+type BulkChunkResult struct {
+	// Recipients are the recipients submitted in the chunk, after normalization
+	Recipients []string
+
+	// Response is nil when Err is set
+	Response *BulkSMSResponse
+	Err      error
+}
+
+// BulkDispatchResult is the aggregated result of DispatchBulk
+type BulkDispatchResult struct {
+	// Recipients maps every recipient, as given in the request, to its outcome
+	// Duplicate recipients share the outcome of the single SMS they were sent
+	Recipients map[string]RecipientResult
+
+	// Chunks are the submitted chunks, in order
+	Chunks []BulkChunkResult
+}
+
+// Failed returns the recipients which were not sent, in sorted order
+func (r *BulkDispatchResult) Failed() []string {
+	failed := []string{}
+	for to, result := range r.Recipients {
+		if result.Err != nil {
+			failed = append(failed, to)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// DispatchBulk sends r with c.SendBulkSMS, splitting its recipients into chunks which are submitted concurrently
+// Recipients are trimmed, validated and deduplicated first. Invalid recipients are reported in the result and never sent
+// It only returns an error when From or Message are invalid, failures of individual chunks are reported in the result
+func DispatchBulk(ctx context.Context, c Client, r *BulkSMSRequest, opts *DispatchOptions) (*BulkDispatchResult, error) {
+	return dispatchBulk(ctx, r, opts, func(ctx context.Context, to []string) (*BulkSMSResponse, error) {
+		bsr, _, err := c.SendBulkSMS(ctx, strings.Join(to, ","), r.From, r.Message)
+		return bsr, err
+	})
+}
+
+// DispatchBulk dispatches r like the package level DispatchBulk function
+// Chunks are sent with SendBulk, so every field of r is kept, and opts.NormalizeRecipient defaults to Config.NormalizeRecipient
+// Config.MaxSegments and Config.SenderIDs are checked before any chunk is sent
+func (j *Jusibe) DispatchBulk(ctx context.Context, r *BulkSMSRequest, opts *DispatchOptions) (result *BulkDispatchResult, err error) {
+	opts = opts.withDefaults()
+	if opts.NormalizeRecipient == nil {
+		opts.NormalizeRecipient = j.normalizeRecipient
+	}
+
+	req := *r
+	if req.MaxSegments <= 0 {
+		req.MaxSegments = j.maxSegments
+	}
+
+	if err = ValidateSenderID(req.From); err != nil {
+		return
+	}
+	if err = j.senderIDs.check(req.From); err != nil {
+		return
+	}
+
+	// Recipients are normalized once by dispatchBulk, so chunks skip Config.NormalizeRecipient
+	return dispatchBulk(ctx, &req, opts, func(ctx context.Context, to []string) (*BulkSMSResponse, error) {
+		chunk := req
+		chunk.To = to
+		bsr, _, err := j.sendBulk(ctx, &chunk, false)
+		return bsr, err
+	})
+}
+
+// dispatchBulk validates and deduplicates the recipients of r, then submits them in chunks with send
+func dispatchBulk(ctx context.Context, r *BulkSMSRequest, opts *DispatchOptions, send func(ctx context.Context, to []string) (*BulkSMSResponse, error)) (result *BulkDispatchResult, err error) {
+	opts = opts.withDefaults()
+
+	if err = ValidateSenderID(r.From); err != nil {
+		return
+	}
+	if err = messageIsValid(r.Message, r.MaxSegments); err != nil {
+		return
+	}
+
+	result = &BulkDispatchResult{Recipients: map[string]RecipientResult{}}
+
+	// aliases maps every unique recipient to the recipients of r it stands for
+	aliases := map[string][]string{}
+	unique := []string{}
+	for _, to := range r.To {
+		if _, ok := result.Recipients[to]; ok {
+			continue
+		}
+
+		recipient, recipientErr := strings.TrimSpace(to), recipientIsValid(to)
+		if recipientErr == nil && opts.NormalizeRecipient != nil {
+			if recipient, recipientErr = opts.NormalizeRecipient(recipient); recipientErr != nil {
+				recipientErr = &RecipientError{Recipient: to, Err: recipientErr}
+			}
+		}
+		if recipientErr != nil {
+			result.Recipients[to] = RecipientResult{Err: recipientErr}
+			continue
+		}
+
+		// Placeholder until the chunk of the recipient is submitted
+		result.Recipients[to] = RecipientResult{}
+		if _, ok := aliases[recipient]; !ok {
+			unique = append(unique, recipient)
+		}
+		aliases[recipient] = append(aliases[recipient], to)
+	}
+
+	for start := 0; start < len(unique); start += opts.ChunkSize {
+		end := start + opts.ChunkSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		result.Chunks = append(result.Chunks, BulkChunkResult{Recipients: unique[start:end]})
+	}
+
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	workers := opts.Concurrency
+	if workers > len(result.Chunks) {
+		workers = len(result.Chunks)
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for n := range chunks {
+				chunk := result.Chunks[n]
+				if chunk.Err = ctx.Err(); chunk.Err == nil {
+					chunk.Response, chunk.Err = send(ctx, chunk.Recipients)
+				}
+				if chunk.Err != nil {
+					chunk.Response = nil
+				}
+
+				mu.Lock()
+				result.Chunks[n] = chunk
+				for _, recipient := range chunk.Recipients {
+					for _, to := range aliases[recipient] {
+						if chunk.Err != nil {
+							result.Recipients[to] = RecipientResult{Err: chunk.Err}
+						} else {
+							result.Recipients[to] = RecipientResult{BulkMessageID: chunk.Response.MessageID}
+						}
+					}
+				}
+				mu.Unlock()
+
+				if opts.OnChunk != nil {
+					opts.OnChunk(chunk)
+				}
+			}
+		}()
+	}
+
+	for i := range result.Chunks {
+		chunks <- i
+	}
+	close(chunks)
+	wg.Wait()
+
+	return
+}
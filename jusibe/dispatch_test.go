@@ -0,0 +1,137 @@
+package jusibe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibefake"
+	"github.com/azeezolaniran2016/jusibe-go/jusibetest"
+	"github.com/azeezolaniran2016/jusibe-go/msisdn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatchBulk(t *testing.T) {
+	t.Run("DispatchBulk should chunk, deduplicate and validate recipients", func(t *testing.T) {
+		fake := jusibefake.New(100)
+
+		to := []string{}
+		for i := 0; i < 10; i++ {
+			to = append(to, fmt.Sprintf("0803000000%d", i))
+		}
+		to = append(to, "08030000000", " ", "08030000001,08030000002")
+
+		var mu sync.Mutex
+		chunkSizes := []int{}
+		opts := &jusibe.DispatchOptions{
+			ChunkSize:   3,
+			Concurrency: 2,
+			OnChunk: func(c jusibe.BulkChunkResult) {
+				mu.Lock()
+				defer mu.Unlock()
+				chunkSizes = append(chunkSizes, len(c.Recipients))
+			},
+		}
+
+		result, err := jusibe.DispatchBulk(context.Background(), fake, &jusibe.BulkSMSRequest{To: to, From: "test_user", Message: "Hello World!"}, opts)
+		assert.NoError(t, err)
+
+		assert.Len(t, result.Chunks, 4)
+		assert.ElementsMatch(t, []int{3, 3, 3, 1}, chunkSizes)
+		assert.Len(t, fake.Sent(), 10, "duplicates should only be sent once")
+		assert.Equal(t, 90, fake.Credits())
+
+		assert.Len(t, result.Recipients, 12)
+		assert.Equal(t, []string{" ", "08030000001,08030000002"}, result.Failed())
+		assert.True(t, errors.Is(result.Recipients[" "].Err, jusibe.ErrInvalidRecipient))
+
+		for i, chunk := range result.Chunks {
+			assert.NoError(t, chunk.Err)
+			for _, recipient := range chunk.Recipients {
+				assert.Equal(t, chunk.Response.MessageID, result.Recipients[recipient].BulkMessageID, "chunk %d", i)
+			}
+		}
+	})
+
+	t.Run("DispatchBulk should report chunk failures per recipient", func(t *testing.T) {
+		fake := jusibefake.New(100)
+		fake.Fail(jusibefake.OpSendBulkSMS, &jusibe.APIError{StatusCode: http.StatusServiceUnavailable})
+
+		to := []string{"08030000000", "08030000001", "08030000002"}
+		result, err := jusibe.DispatchBulk(context.Background(), fake, &jusibe.BulkSMSRequest{To: to, From: "test_user", Message: "Hello World!"}, &jusibe.DispatchOptions{ChunkSize: 2, Concurrency: 1})
+		assert.NoError(t, err)
+
+		assert.True(t, errors.Is(result.Chunks[0].Err, jusibe.ErrServer))
+		assert.Nil(t, result.Chunks[0].Response)
+		assert.NoError(t, result.Chunks[1].Err)
+		assert.Equal(t, []string{"08030000000", "08030000001"}, result.Failed())
+		assert.NotEmpty(t, result.Recipients["08030000002"].BulkMessageID)
+	})
+
+	t.Run("DispatchBulk should refuse an invalid sender or message", func(t *testing.T) {
+		fake := jusibefake.New(100)
+
+		_, err := jusibe.DispatchBulk(context.Background(), fake, &jusibe.BulkSMSRequest{To: []string{"08030000000"}, From: "Café", Message: "Hello World!"}, nil)
+		assert.True(t, errors.Is(err, jusibe.ErrInvalidSender))
+
+		_, err = jusibe.DispatchBulk(context.Background(), fake, &jusibe.BulkSMSRequest{To: []string{"08030000000"}, From: "test_user"}, nil)
+		assert.True(t, errors.Is(err, jusibe.ErrEmptyMessage))
+		assert.Empty(t, fake.Sent())
+	})
+
+	t.Run("Jusibe.DispatchBulk should normalize recipients before deduplication", func(t *testing.T) {
+		srv := jusibetest.NewServer("some_public_key", "some_access_token", 100)
+		defer srv.Close()
+
+		var mu sync.Mutex
+		normalized := 0
+		cfg := srv.Config()
+		cfg.NormalizeRecipient = func(to string) (string, error) {
+			mu.Lock()
+			normalized++
+			mu.Unlock()
+			return msisdn.Normalize(to)
+		}
+		j, err := jusibe.New(cfg)
+		assert.NoError(t, err)
+
+		to := []string{"08030000000", "+234 803 000 0000", "0803000"}
+		result, err := j.DispatchBulk(context.Background(), &jusibe.BulkSMSRequest{To: to, From: "test_user", Message: "Hello World!"}, nil)
+		assert.NoError(t, err)
+
+		assert.Len(t, result.Chunks, 1)
+		assert.Equal(t, []string{"2348030000000"}, result.Chunks[0].Recipients)
+		assert.Equal(t, result.Recipients["08030000000"], result.Recipients["+234 803 000 0000"])
+		assert.True(t, errors.Is(result.Recipients["0803000"].Err, msisdn.ErrInvalid))
+		assert.Len(t, srv.Sent(), 1)
+		assert.Equal(t, len(to), normalized, "recipients should be normalized once")
+	})
+
+	t.Run("Jusibe.DispatchBulk should apply Config.MaxSegments and Config.SenderIDs before sending", func(t *testing.T) {
+		srv := jusibetest.NewServer("some_public_key", "some_access_token", 100)
+		defer srv.Close()
+
+		senderIDs, err := jusibe.NewSenderIDRegistry("MyShop")
+		assert.NoError(t, err)
+
+		cfg := srv.Config()
+		cfg.MaxSegments = 1
+		cfg.SenderIDs = senderIDs
+		j, err := jusibe.New(cfg)
+		assert.NoError(t, err)
+
+		to := []string{"08030000000", "08030000001"}
+		_, err = j.DispatchBulk(context.Background(), &jusibe.BulkSMSRequest{To: to, From: "MyShop", Message: strings.Repeat("a", 161)}, nil)
+		assert.True(t, errors.Is(err, jusibe.ErrMessageTooLong))
+
+		_, err = j.DispatchBulk(context.Background(), &jusibe.BulkSMSRequest{To: to, From: "Other", Message: "Hello World!"}, nil)
+		assert.True(t, errors.Is(err, jusibe.ErrSenderIDNotAllowed))
+
+		assert.Equal(t, 0, srv.Requests())
+	})
+}
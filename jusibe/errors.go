@@ -0,0 +1,128 @@
+package jusibe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized is matched by an *APIError when Jusibe rejects the PublicKey/AccessToken pair
+	ErrUnauthorized = errors.New("jusibe: unauthorized")
+
+	// ErrInsufficientCredits is matched by an *APIError when the account does not have enough SMS credits
+	ErrInsufficientCredits = errors.New("jusibe: insufficient sms credits")
+
+	// ErrInvalidRecipient is matched by an *APIError when Jusibe rejects the `to` parameter
+	ErrInvalidRecipient = errors.New("jusibe: invalid recipient")
+
+	// ErrInvalidSender is matched by an *APIError when Jusibe rejects the `from` (SenderID) parameter, and by a *SenderIDError
+	ErrInvalidSender = errors.New("jusibe: invalid sender id")
+
+	// ErrNotFound is matched by an *APIError when the requested resource (e.g message id) does not exist
+	ErrNotFound = errors.New("jusibe: not found")
+
+	// ErrRateLimited is matched by an *APIError when Jusibe throttles the request
+	ErrRateLimited = errors.New("jusibe: rate limited")
+
+	// ErrMessageTooLong is returned when a message would be split into more parts than Config.MaxSegments allows
+	ErrMessageTooLong = errors.New("jusibe: message too long")
+
+	// ErrEmptyMessage is returned when sending an SMS without a message
+	ErrEmptyMessage = errors.New("jusibe: empty message")
+
+	// ErrCircuitOpen is returned without making any http request while the circuit breaker configured by Config.CircuitBreaker is open
+	ErrCircuitOpen = errors.New("jusibe: circuit breaker is open")
+
+	// ErrServer is matched by an *APIError when Jusibe responds with a 5xx http response code
+	ErrServer = errors.New("jusibe: server error")
+)
+
+// ErrorResponse is the error payload returned by Jusibe on non-2xx responses
+type ErrorResponse struct {
+	Error             string            `json:"error,omitempty"`
+	Message           string            `json:"message,omitempty"`
+	InvalidParameters map[string]string `json:"invalid_parameters,omitempty"`
+}
+
+// description returns the most descriptive message available in the error payload
+func (er ErrorResponse) description() string {
+	if er.Error != "" {
+		return er.Error
+	}
+	return er.Message
+}
+
+// APIError is returned when Jusibe responds with a non-2xx http response code
+// Use errors.Is with the Err* sentinels to branch on the failure class
+type APIError struct {
+	// StatusCode is the http response code returned by Jusibe
+	StatusCode int
+
+	// Endpoint is the path of the request which failed, e.g /smsapi/send_sms
+	Endpoint string
+
+	// Response is the decoded Jusibe error payload. It is empty when the body isn't valid JSON
+	Response ErrorResponse
+
+	// Body is the raw response body
+	Body []byte
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("unexpected %d http response code from %s", e.StatusCode, e.Endpoint)
+	if desc := e.Response.description(); desc != "" {
+		msg = fmt.Sprintf("%s: %s", msg, desc)
+	}
+	return msg
+}
+
+// Is reports whether the APIError belongs to the failure class of the target sentinel
+func (e *APIError) Is(target error) bool {
+	desc := strings.ToLower(e.Response.description())
+
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrInsufficientCredits:
+		return e.StatusCode == http.StatusPaymentRequired || strings.Contains(desc, "credit")
+	case ErrInvalidRecipient:
+		_, ok := e.Response.InvalidParameters["to"]
+		return ok || strings.Contains(desc, "recipient") || strings.Contains(desc, "phone number")
+	case ErrInvalidSender:
+		_, ok := e.Response.InvalidParameters["from"]
+		return ok || strings.Contains(desc, "sender")
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+
+	return false
+}
+
+// RecipientError is returned when a recipient fails validation or Config.NormalizeRecipient rejects it, before any http request is made
+// It matches ErrInvalidRecipient with errors.Is and unwraps to the underlying error
+type RecipientError struct {
+	Recipient string
+	Err       error
+}
+
+// Error implements the error interface
+func (e *RecipientError) Error() string {
+	return fmt.Sprintf("invalid recipient %q: %s", e.Recipient, e.Err)
+}
+
+// Is reports whether target is ErrInvalidRecipient
+func (e *RecipientError) Is(target error) bool {
+	return target == ErrInvalidRecipient
+}
+
+// Unwrap returns the underlying error
+func (e *RecipientError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,70 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Run("doHTTPRequest should return *APIError with decoded body on non-2xx response", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key"}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		bodyBytes := []byte(`{"error": "Invalid API Key!"}`)
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: http.StatusUnauthorized}
+			res.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+			return res, nil
+		})
+
+		_, res, err := jusibe.CheckSMSCredits(context.Background())
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr), "should return *APIError")
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+		assert.Equal(t, "/smsapi/get_credits", apiErr.Endpoint)
+		assert.Equal(t, "Invalid API Key!", apiErr.Response.Error)
+		assert.Equal(t, bodyBytes, apiErr.Body)
+		assert.Equal(t, "unexpected 401 http response code from /smsapi/get_credits: Invalid API Key!", apiErr.Error())
+
+		assert.True(t, errors.Is(err, ErrUnauthorized))
+		assert.False(t, errors.Is(err, ErrInsufficientCredits))
+	})
+
+	t.Run("APIError should keep raw body when payload isn't JSON", func(t *testing.T) {
+		apiErr := &APIError{StatusCode: http.StatusBadGateway, Endpoint: "/smsapi/send_sms", Body: []byte("<html>")}
+
+		assert.Equal(t, "unexpected 502 http response code from /smsapi/send_sms", apiErr.Error())
+		assert.True(t, errors.Is(apiErr, ErrServer))
+	})
+
+	t.Run("APIError should match failure classes", func(t *testing.T) {
+		credits := &APIError{StatusCode: http.StatusBadRequest, Response: ErrorResponse{Error: "Insufficient SMS credits"}}
+		assert.True(t, errors.Is(credits, ErrInsufficientCredits))
+
+		recipient := &APIError{StatusCode: http.StatusBadRequest, Response: ErrorResponse{InvalidParameters: map[string]string{"to": "invalid"}}}
+		assert.True(t, errors.Is(recipient, ErrInvalidRecipient))
+		assert.False(t, errors.Is(recipient, ErrInvalidSender))
+
+		sender := &APIError{StatusCode: http.StatusBadRequest, Response: ErrorResponse{Error: "Invalid sender ID"}}
+		assert.True(t, errors.Is(sender, ErrInvalidSender))
+
+		assert.True(t, errors.Is(&APIError{StatusCode: http.StatusNotFound}, ErrNotFound))
+		assert.True(t, errors.Is(&APIError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited))
+	})
+}
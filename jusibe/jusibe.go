@@ -4,52 +4,53 @@ Package jusibe provides a Jusibe API client.
 Refer to https://jusibe.com/docs/ for more information about Jusibe.
 
 Example Usage:
-		// Create the Jusibe Configuration. Note that your AccessToken and PublicKey are required
-		cfg := &jusibe.Config{
-			PublicKey: os.Getenv("JUSIBE_PUBLIC_KEY"),
-			AccessToken: os.Getenv("JUSIBE_ACCESS_TOKEN"),
-		}
 
-    // Create the client
-    j, err := jusibe.New(cfg)
-    if err != nil {
-        log.Fatal(err)
-		}
+	// Create the Jusibe Configuration. Note that your AccessToken and PublicKey are required
+	cfg := &jusibe.Config{
+		PublicKey:   os.Getenv("JUSIBE_PUBLIC_KEY"),
+		AccessToken: os.Getenv("JUSIBE_ACCESS_TOKEN"),
+	}
 
-		// Send SMS
-		to, from, message := "08000000000000", "Azeez", "Hello World"
-		smsResponse, _, err := j.SendSMS(context.Background(), to, from, message)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Create the client
+	j, err := jusibe.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		// Check Delivery Status
-		deliveryResponse, _, err := j.CheckSMSDeliveryStatus(context.Background(), smsResponse.MessageID)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Printf("%+v\n", deliveryResponse)
+	// Send SMS
+	to, from, message := "08000000000000", "Azeez", "Hello World"
+	smsResponse, _, err := j.SendSMS(context.Background(), to, from, message)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		// Send Bulk SMS
-		to, from, message := "08000000000000,08050000000,08090000000", "Azeez", "Hello World"
-		bulkSMSResponse, _, err := j.SendBulkSMS(context.Background(), to, from, message)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Check Delivery Status
+	deliveryResponse, _, err := j.CheckSMSDeliveryStatus(context.Background(), smsResponse.MessageID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%+v\n", deliveryResponse)
 
-		// Check Bulk SMS Status
-		status, _, err := j.CheckBulkSMSStatus(context.Background(), bulkSMSResponse.BulkMessageID)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Printf("%+v\n", status)
+	// Send Bulk SMS
+	to, from, message := "08000000000000,08050000000,08090000000", "Azeez", "Hello World"
+	bulkSMSResponse, _, err := j.SendBulkSMS(context.Background(), to, from, message)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		// Get SMS credits
-		creditsResponse, _, err := j.CheckSMSCredits(context.Background())
-		if err != nil {
-			log.Fatal("err")
-		}
-		fmt.Printf("%+v\n", creditsResponse)
+	// Check Bulk SMS Status
+	status, _, err := j.CheckBulkSMSStatus(context.Background(), bulkSMSResponse.BulkMessageID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%+v\n", status)
+
+	// Get SMS credits
+	creditsResponse, _, err := j.CheckSMSCredits(context.Background())
+	if err != nil {
+		log.Fatal("err")
+	}
+	fmt.Printf("%+v\n", creditsResponse)
 */
 package jusibe
 
@@ -58,12 +59,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
 const (
-	apiBaseURL               = "https://jusibe.com/smsapi"
+	defaultAPIBaseURL        = "https://jusibe.com/smsapi"
 	defaultHTTPClientTimeout = (time.Second * 10)
 )
 
@@ -72,6 +77,60 @@ const (
 type Config struct {
 	AccessToken string
 	PublicKey   string
+
+	// BaseURL is the Jusibe API base URL every endpoint is resolved against, e.g https://staging.example.com/smsapi
+	// It defaults to https://jusibe.com/smsapi when empty
+	BaseURL string
+
+	// RetryPolicy enables automatic retries of transient failures. Requests are not retried when nil
+	RetryPolicy *RetryPolicy
+
+	// SendRateLimit limits the rate of SendSMS and SendBulkSMS requests. Sends are not limited when nil
+	SendRateLimit *RateLimit
+
+	// StatusRateLimit limits the rate of CheckSMSCredits, CheckSMSDeliveryStatus and CheckBulkSMSStatus requests
+	// Status and credit checks are not limited when nil
+	StatusRateLimit *RateLimit
+
+	// Location is the timezone Jusibe timestamps are parsed in. It defaults to Africa/Lagos when nil
+	Location *time.Location
+
+	// NormalizeRecipient, when set, is applied to every recipient of Send and SendBulk before the request is made
+	// Returning an error rejects the send with a *RecipientError. Use msisdn.Normalize to validate phone numbers
+	NormalizeRecipient func(to string) (string, error)
+
+	// SenderIDs, when set, makes Send and SendBulk refuse SenderIDs missing from the registry with a *SenderIDError
+	SenderIDs *SenderIDRegistry
+
+	// Middleware wraps every API call, in order: the first middleware is the outermost one
+	// Middleware sees a single call even when the client retries it
+	Middleware []Middleware
+
+	// Logger, when set, records the operation, endpoint, status code, latency, message id and error of every call
+	// It wraps Metrics, Middleware and CircuitBreaker, and redacts values according to LogOptions
+	// Calls go through Tracer, then Logger, then Metrics, then Middleware, then CircuitBreaker
+	Logger     Logger
+	LogOptions LogOptions
+
+	// Metrics, when set, receives the operation, status code, latency, error and credit figures of every call
+	Metrics Metrics
+
+	// Tracer, when set, wraps every call in a span annotated with the operation, status code, message id, credits used
+	// and error, and propagates the trace context on the outbound request. It wraps every other Middleware, including Logger
+	Tracer Tracer
+
+	// CircuitBreaker, when set, fails calls fast with ErrCircuitOpen after repeated failures, instead of waiting on
+	// the http client timeout while Jusibe is down. Calls are not guarded when nil
+	CircuitBreaker *CircuitBreaker
+
+	// QueryMode sends the parameters of Send and SendBulk in the URL query string instead of a form-encoded POST body
+	// Parameters are escaped in both modes, but the body keeps message text and recipients out of URLs and access logs
+	QueryMode bool
+
+	// MaxSegments, when greater than zero, makes Send and SendBulk refuse messages which would be split
+	// into more SMS parts, with ErrMessageTooLong. See the segment package for how parts are counted
+	// SendSMSRequest.MaxSegments and BulkSMSRequest.MaxSegments override it per request
+	MaxSegments int
 }
 
 // Jusibe is Jusibe API client
@@ -79,14 +138,40 @@ type Jusibe struct {
 	httpClient  *http.Client
 	publicKey   string
 	accessToken string
+	baseURL     string
+	retryPolicy *RetryPolicy
+
+	sendRateLimiter   *rateLimiter
+	statusRateLimiter *rateLimiter
+
+	location  *time.Location
+	queryMode bool
+
+	normalizeRecipient func(to string) (string, error)
+	maxSegments        int
+	senderIDs          *SenderIDRegistry
+
+	circuitBreaker *circuitBreaker
+	handler        CallHandler
 }
 
 // createHTTPRequest is a helper method for creating *http.Request used in external API calls
+// params are escaped into the query string of GET requests, and into a form-encoded body of other requests unless QueryMode is set
 // It returns a *http.Request which has Basic Auth and Context set
-func (j *Jusibe) createHTTPRequest(ctx context.Context, method, endpoint string) (req *http.Request, err error) {
-	req, err = http.NewRequest(method, (apiBaseURL + endpoint), nil)
+func (j *Jusibe) createHTTPRequest(ctx context.Context, method, endpoint string, params url.Values) (req *http.Request, err error) {
+	var body io.Reader
+	if method != http.MethodGet && !j.queryMode {
+		body = strings.NewReader(params.Encode())
+	} else if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err = http.NewRequest(method, (j.baseURL + endpoint), body)
 
 	if err == nil {
+		if body != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
 		req.SetBasicAuth(j.publicKey, j.accessToken)
 		req = req.WithContext(ctx)
 	}
@@ -94,11 +179,38 @@ func (j *Jusibe) createHTTPRequest(ctx context.Context, method, endpoint string)
 	return
 }
 
-// doHTTPRequest performs http requests
+// doHTTPRequest performs http requests, retrying transient failures according to the client RetryPolicy
+// Every attempt waits for the client rate limiter, if any
 // It writes the response body into the body parameter before closing the response body
-// It returns the *http.Response for convinience to its caller
+// It returns the *http.Response of the last attempt for convinience to its caller
 func (j *Jusibe) doHTTPRequest(req *http.Request, body interface{}) (res *http.Response, err error) {
-	req.URL.RawQuery = req.URL.Query().Encode()
+	attempts := j.retryPolicy.attemptsFor(req)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return
+			}
+		}
+
+		if err = j.rateLimiterFor(req).wait(req.Context()); err != nil {
+			return
+		}
+
+		res, err = j.doHTTPRequestOnce(req, body)
+		if attempt >= attempts || !j.retryPolicy.shouldRetry(req.Context(), res, err) {
+			return
+		}
+
+		if waitErr := j.retryPolicy.wait(req.Context(), attempt, res); waitErr != nil {
+			err = waitErr
+			return
+		}
+	}
+}
+
+// doHTTPRequestOnce performs a single http request attempt
+func (j *Jusibe) doHTTPRequestOnce(req *http.Request, body interface{}) (res *http.Response, err error) {
 	res, err = j.httpClient.Do(req)
 	if err != nil {
 		return
@@ -112,7 +224,7 @@ func (j *Jusibe) doHTTPRequest(req *http.Request, body interface{}) (res *http.R
 	}()
 
 	if res.StatusCode > 299 || res.StatusCode < 200 {
-		err = fmt.Errorf("unexpected %d http response code", res.StatusCode)
+		err = newAPIError(req, res)
 		return
 	}
 
@@ -121,67 +233,67 @@ func (j *Jusibe) doHTTPRequest(req *http.Request, body interface{}) (res *http.R
 	return
 }
 
-func fromIsValid(from string) (err error) {
-	if len(from) > 11 {
-		err = errors.New("from (SenderID) allows maximum of eleven (11) characters. See API docs https://jusibe.com/docs/")
+// newAPIError reads the body of a non-2xx *http.Response into an *APIError
+func newAPIError(req *http.Request, res *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Endpoint:   req.URL.Path,
 	}
-	return
-}
 
-// SendSMS sends SMS to the /send_sms endpoint
-// It also returns a *http.Response for convinience to its caller, along with a *SMSResponse and error
-func (j *Jusibe) SendSMS(ctx context.Context, to, from, message string) (ssr *SMSResponse, res *http.Response, err error) {
-	// This check is defined in Jusibe API docs
-	if err = fromIsValid(from); err != nil {
-		return
+	body, readErr := ioutil.ReadAll(res.Body)
+	if readErr == nil {
+		apiErr.Body = body
+		// The payload is best effort, Body still holds whatever Jusibe returned
+		_ = json.Unmarshal(body, &apiErr.Response)
 	}
 
-	endpoint := fmt.Sprintf("/send_sms?to=%s&from=%s&message=%s", to, from, message)
+	return apiErr
+}
 
-	req, err := j.createHTTPRequest(ctx, http.MethodPost, endpoint)
+// baseURLIsValid checks that baseURL is an absolute http(s) URL
+func baseURLIsValid(baseURL string) (err error) {
+	u, err := url.Parse(baseURL)
 	if err != nil {
-		return
+		return fmt.Errorf("invalid BaseURL %q: %s", baseURL, err)
 	}
 
-	ssr = new(SMSResponse)
-	res, err = j.doHTTPRequest(req, ssr)
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = fmt.Errorf("invalid BaseURL %q: must be an absolute http or https URL", baseURL)
+	} else if u.RawQuery != "" || u.Fragment != "" {
+		err = fmt.Errorf("invalid BaseURL %q: must not contain a query or fragment", baseURL)
+	}
 
 	return
 }
 
+// SendSMS sends SMS to the /send_sms endpoint
+// It is a shorthand for Send with a SendSMSRequest
+// It also returns a *http.Response for convinience to its caller, along with a *SMSResponse and error
+func (j *Jusibe) SendSMS(ctx context.Context, to, from, message string) (*SMSResponse, *http.Response, error) {
+	return j.Send(ctx, &SendSMSRequest{To: to, From: from, Message: message})
+}
+
 // SendBulkSMS sends SMS to the /bulk/send_sms endpoint
+// It is a shorthand for SendBulk with a BulkSMSRequest, to is a comma separated list of recipients
 // It also returns a *http.Response for convinience to its caller, along with a *BulkSMSResponse and error
-func (j *Jusibe) SendBulkSMS(ctx context.Context, to, from, message string) (bsr *BulkSMSResponse, res *http.Response, err error) {
-	// This check is defined in Jusibe API docs
-	if err = fromIsValid(from); err != nil {
-		return
-	}
-
-	url := fmt.Sprintf("/bulk/send_sms?to=%s&from=%s&message=%s", to, from, message)
-
-	req, err := j.createHTTPRequest(ctx, http.MethodPost, url)
-	if err != nil {
-		return
-	}
-
-	bsr = new(BulkSMSResponse)
-	res, err = j.doHTTPRequest(req, bsr)
-
-	return
+func (j *Jusibe) SendBulkSMS(ctx context.Context, to, from, message string) (*BulkSMSResponse, *http.Response, error) {
+	return j.SendBulk(ctx, &BulkSMSRequest{To: SplitRecipients(to), From: from, Message: message})
 }
 
 // CheckSMSCredits checks SMS credits using the /get_credits endpoint
 // It also returns a *http.Response for convinience to its caller, along with a *SMSCreditsReponse and error
 func (j *Jusibe) CheckSMSCredits(ctx context.Context) (scr *SMSCreditsResponse, res *http.Response, err error) {
 	endpoint := "/get_credits"
-	req, err := j.createHTTPRequest(ctx, http.MethodGet, endpoint)
+	req, err := j.createHTTPRequest(ctx, http.MethodGet, endpoint, nil)
 
 	if err != nil {
 		return
 	}
 
 	scr = &SMSCreditsResponse{}
-	res, err = j.doHTTPRequest(req, scr)
+	call := &Call{Operation: OpCheckSMSCredits, Request: req, Result: scr}
+	err = j.invoke(ctx, call)
+	res = call.Response
 
 	return
 }
@@ -189,15 +301,17 @@ func (j *Jusibe) CheckSMSCredits(ctx context.Context) (scr *SMSCreditsResponse,
 // CheckSMSDeliveryStatus checks a sent SMS (specified by a message id) delivery status using the /delivery_status endpoint
 // It also returns a *http.Response for convinience to its caller, along with a *SMSDeliveryResponse and error
 func (j *Jusibe) CheckSMSDeliveryStatus(ctx context.Context, messageID string) (sds *SMSDeliveryResponse, res *http.Response, err error) {
-	endpoint := "/delivery_status?message_id=" + messageID
-	req, err := j.createHTTPRequest(ctx, http.MethodGet, endpoint)
+	endpoint := "/delivery_status"
+	req, err := j.createHTTPRequest(ctx, http.MethodGet, endpoint, url.Values{"message_id": {messageID}})
 
 	if err != nil {
 		return
 	}
 
-	sds = new(SMSDeliveryResponse)
-	res, err = j.doHTTPRequest(req, sds)
+	sds = &SMSDeliveryResponse{location: j.location}
+	call := &Call{Operation: OpCheckSMSDeliveryStatus, Request: req, MessageID: messageID, Result: sds}
+	err = j.invoke(ctx, call)
+	res = call.Response
 
 	return
 }
@@ -205,15 +319,17 @@ func (j *Jusibe) CheckSMSDeliveryStatus(ctx context.Context, messageID string) (
 // CheckBulkSMSStatus checks BulkSMS (specified by a message id) delivery status using the /bulk/status endpoint
 // It also returns a *http.Response for convinience to its caller, along with a *SMSDeliveryResponse and error
 func (j *Jusibe) CheckBulkSMSStatus(ctx context.Context, messageID string) (sds *BulkSMSStatusResponse, res *http.Response, err error) {
-	endpoint := "/bulk/status?bulk_message_id=" + messageID
-	req, err := j.createHTTPRequest(ctx, http.MethodGet, endpoint)
+	endpoint := "/bulk/status"
+	req, err := j.createHTTPRequest(ctx, http.MethodGet, endpoint, url.Values{"bulk_message_id": {messageID}})
 
 	if err != nil {
 		return
 	}
 
-	sds = new(BulkSMSStatusResponse)
-	res, err = j.doHTTPRequest(req, sds)
+	sds = &BulkSMSStatusResponse{location: j.location}
+	call := &Call{Operation: OpCheckBulkSMSStatus, Request: req, MessageID: messageID, Result: sds}
+	err = j.invoke(ctx, call)
+	res = call.Response
 
 	return
 }
@@ -236,10 +352,71 @@ func NewWithHTTPClient(cfg *Config, httpClient *http.Client) (j *Jusibe, err err
 		return
 	}
 
+	baseURL := defaultAPIBaseURL
+	if cfg.BaseURL != "" {
+		if err = baseURLIsValid(cfg.BaseURL); err != nil {
+			return
+		}
+		baseURL = strings.TrimRight(cfg.BaseURL, "/")
+	}
+
+	var retryPolicy *RetryPolicy
+	if cfg.RetryPolicy != nil {
+		retryPolicy = cfg.RetryPolicy.withDefaults()
+		if err = retryPolicy.validate(); err != nil {
+			return
+		}
+	}
+
 	j = &Jusibe{
 		httpClient:  httpClient,
 		accessToken: cfg.AccessToken,
 		publicKey:   cfg.PublicKey,
+		baseURL:     baseURL,
+		retryPolicy: retryPolicy,
+		location:    cfg.Location,
+		queryMode:   cfg.QueryMode,
+
+		normalizeRecipient: cfg.NormalizeRecipient,
+		maxSegments:        cfg.MaxSegments,
+		senderIDs:          cfg.SenderIDs,
+	}
+
+	if cfg.CircuitBreaker != nil {
+		if err = cfg.CircuitBreaker.validate(); err != nil {
+			return nil, err
+		}
+		j.circuitBreaker = newCircuitBreaker(cfg.CircuitBreaker)
+	}
+
+	// The circuit breaker is the innermost middleware, so every other middleware observes ErrCircuitOpen
+	middleware := append([]Middleware(nil), cfg.Middleware...)
+	if j.circuitBreaker != nil {
+		middleware = append(middleware, j.circuitBreaker.middleware())
+	}
+	if cfg.Metrics != nil {
+		middleware = append([]Middleware{metricsMiddleware(cfg.Metrics)}, middleware...)
+	}
+	if cfg.Logger != nil {
+		middleware = append([]Middleware{j.loggingMiddleware(cfg.Logger, cfg.LogOptions)}, middleware...)
+	}
+	if cfg.Tracer != nil {
+		middleware = append([]Middleware{tracingMiddleware(cfg.Tracer)}, middleware...)
+	}
+	j.handler = chain(j.perform, middleware)
+
+	if cfg.SendRateLimit != nil {
+		if err = cfg.SendRateLimit.validate(); err != nil {
+			return nil, err
+		}
+		j.sendRateLimiter = newRateLimiter(cfg.SendRateLimit)
+	}
+
+	if cfg.StatusRateLimit != nil {
+		if err = cfg.StatusRateLimit.validate(); err != nil {
+			return nil, err
+		}
+		j.statusRateLimiter = newRateLimiter(cfg.StatusRateLimit)
 	}
 
 	return
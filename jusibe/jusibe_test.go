@@ -3,12 +3,15 @@ package jusibe
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/azeezolaniran2016/jusibe-go/msisdn"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -73,6 +76,46 @@ func TestJusibe(t *testing.T) {
 		assert.Equal(t, accessToken, jusibe.accessToken, "Should set accessToken")
 	})
 
+	t.Run("New should default BaseURL", func(t *testing.T) {
+		jusibe, err := New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, defaultAPIBaseURL, jusibe.baseURL, "Should set baseURL to default")
+	})
+
+	t.Run("New should validate BaseURL", func(t *testing.T) {
+		accessToken, publicKey := "some_access_token", "some_public_key"
+
+		for _, baseURL := range []string{"jusibe.com/smsapi", "ftp://jusibe.com", "http://", "https://jusibe.com/smsapi?x=1", "://bad"} {
+			_, err := New(&Config{AccessToken: accessToken, PublicKey: publicKey, BaseURL: baseURL})
+			assert.Error(t, err, "should return error for BaseURL %q", baseURL)
+		}
+
+		jusibe, err := New(&Config{AccessToken: accessToken, PublicKey: publicKey, BaseURL: "http://localhost:8080/smsapi/"})
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost:8080/smsapi", jusibe.baseURL, "Should trim trailing slash")
+	})
+
+	t.Run("Endpoints should honour BaseURL", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", BaseURL: "http://staging.local/smsapi"}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "http://staging.local/smsapi/get_credits", req.URL.String())
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"sms_credits": "100"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.CheckSMSCredits(context.Background())
+		assert.NoError(t, err)
+	})
+
 	t.Run("SendSMS", func(t *testing.T) {
 		accessToken, publicKey := "some_access_token", "some_public_key"
 		to, from, message := "09001000101", "test_user", "Hello World!"
@@ -87,7 +130,10 @@ func TestJusibe(t *testing.T) {
 		assert.NoError(t, err, "Should not return error when creating Jusibe instance with NewWithHTTPClient function")
 
 		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
-			assert.Equal(t, "https://jusibe.com/smsapi/send_sms?from=test_user&message=Hello+World%21&to=09001000101", req.URL.String())
+			assert.Equal(t, "https://jusibe.com/smsapi/send_sms", req.URL.String())
+			assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(req.Body)
+			assert.Equal(t, "from=test_user&message=Hello+World%21&to=09001000101", string(body))
 			res := &http.Response{}
 			res.StatusCode = 200
 			bodyBytes := []byte(`{
@@ -104,7 +150,7 @@ func TestJusibe(t *testing.T) {
 
 		assert.Equal(t, 200, res.StatusCode)
 		assert.NoError(t, err)
-		assert.Equal(t, string(StatusSMSSent), s.Status)
+		assert.Equal(t, StatusSMSSent, s.Status)
 		assert.Equal(t, "xyz123", s.MessageID)
 		assert.Equal(t, 1, s.SMSCreditsUsed)
 	})
@@ -123,7 +169,9 @@ func TestJusibe(t *testing.T) {
 		assert.NoError(t, err, "Should not return error when creating Jusibe instance with NewWithHTTPClient function")
 
 		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
-			assert.Equal(t, "https://jusibe.com/smsapi/bulk/send_sms?from=test_user&message=Hello+World%21&to=09001000101%2C08030000000%2C09050000000", req.URL.String())
+			assert.Equal(t, "https://jusibe.com/smsapi/bulk/send_sms", req.URL.String())
+			body, _ := ioutil.ReadAll(req.Body)
+			assert.Equal(t, "from=test_user&message=Hello+World%21&to=09001000101%2C08030000000%2C09050000000", string(body))
 			res := &http.Response{}
 			res.StatusCode = 200
 			bodyBytes := []byte(`{
@@ -139,10 +187,91 @@ func TestJusibe(t *testing.T) {
 
 		assert.Equal(t, 200, res.StatusCode)
 		assert.NoError(t, err)
-		assert.Equal(t, string(StatusBulkSMSSubmitted), s.Status)
+		assert.Equal(t, StatusBulkSMSSubmitted, s.Status)
 		assert.Equal(t, "xeqd6rs3d26", s.MessageID)
 	})
 
+	t.Run("SendBulkSMS should apply NormalizeRecipient", func(t *testing.T) {
+		to, from, message := "0900 100 0101, +234 803-000-0000,", "test_user", "Hello World!"
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", NormalizeRecipient: msisdn.Normalize}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "2349001000101,2348030000000", req.FormValue("to"))
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Submitted", "bulk_message_id": "xeqd6rs3d26"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.SendBulkSMS(context.Background(), to, from, message)
+		assert.NoError(t, err)
+	})
+
+	t.Run("SendBulkSMS should skip empty recipients", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key"}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "09001000101,08030000000", req.FormValue("to"))
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Submitted", "bulk_message_id": "xeqd6rs3d26"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.SendBulkSMS(context.Background(), "09001000101, ,08030000000,", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"09001000101", "08030000000"}, SplitRecipients(" 09001000101,,08030000000 ,"))
+		assert.Empty(t, SplitRecipients(" , "))
+	})
+
+	t.Run("SendSMS should reject recipients before making a request", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", NormalizeRecipient: msisdn.Normalize}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		_, res, err := jusibe.SendSMS(context.Background(), "0803000", "test_user", "Hello World!")
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrInvalidRecipient))
+		assert.True(t, errors.Is(err, msisdn.ErrInvalid))
+
+		var recipientErr *RecipientError
+		assert.True(t, errors.As(err, &recipientErr))
+		assert.Equal(t, "0803000", recipientErr.Recipient)
+	})
+
+	t.Run("SendSMS should refuse messages exceeding MaxSegments", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", MaxSegments: 1}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		_, res, err := jusibe.SendSMS(context.Background(), "09001000101", "test_user", strings.Repeat("Hello World! ", 13))
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrMessageTooLong))
+		assert.EqualError(t, err, "jusibe: message too long: 2 GSM-7 parts exceed the limit of 1")
+
+		_, _, err = jusibe.SendBulkSMS(context.Background(), "09001000101", "test_user", strings.Repeat("👋", 71))
+		assert.True(t, errors.Is(err, ErrMessageTooLong))
+	})
+
 	t.Run("CheckSMSCredits", func(t *testing.T) {
 		accessToken, publicKey := "some_access_token", "some_public_key"
 		cfg := &Config{AccessToken: accessToken, PublicKey: publicKey}
@@ -205,10 +334,14 @@ func TestJusibe(t *testing.T) {
 
 		assert.Equal(t, 200, res.StatusCode)
 		assert.NoError(t, err)
-		assert.Equal(t, string(StatusSMSDelivered), ds.Status)
+		assert.Equal(t, StatusSMSDelivered, ds.Status)
 		assert.Equal(t, "xyz123", ds.MessageID)
 		assert.Equal(t, "2015-05-19 04:34:48", ds.DateSent)
 		assert.Equal(t, "2015-05-19 04:35:05", ds.DateDelivered)
+
+		deliveredAt, err := ds.DeliveredAt()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2015, 5, 19, 3, 35, 5, 0, time.UTC), deliveredAt.UTC())
 	})
 
 	t.Run("CheckBulkSMSDeliveryStatus", func(t *testing.T) {
@@ -247,7 +380,7 @@ func TestJusibe(t *testing.T) {
 		assert.Equal(t, 200, res.StatusCode)
 		assert.NoError(t, err)
 		assert.Equal(t, "xeqd6rs3d26", ds.BulkMessageID)
-		assert.Equal(t, "Completed", ds.Status)
+		assert.Equal(t, StatusBulkSMSCompleted, ds.Status)
 		assert.Equal(t, "2019-04-02 15:23:13", ds.Created)
 		assert.Equal(t, "2019-04-02 15:25:03", ds.Processed)
 		assert.Equal(t, "2", ds.TotalNumbers)
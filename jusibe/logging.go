@@ -0,0 +1,158 @@
+package jusibe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Logger is a structured logger receiving alternating key and value arguments
+// A *slog.Logger from log/slog satisfies it
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// LogOptions controls what Config.Logger records
+// Phone numbers are masked and message bodies are left out unless enabled. Credentials are never logged
+type LogOptions struct {
+	// PhoneNumbers logs recipients unmasked
+	PhoneNumbers bool
+
+	// MessageBodies logs message bodies at debug level
+	MessageBodies bool
+}
+
+// loggingMiddleware logs every call with logger, redacting sensitive values
+func (j *Jusibe) loggingMiddleware(logger Logger, opts LogOptions) Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call *Call) error {
+			args := []interface{}{"operation", string(call.Operation), "endpoint", call.Request.URL.Path}
+			if reference := callReference(call); reference != "" {
+				args = append(args, "reference", reference)
+			}
+			if recipients := callRecipients(call); len(recipients) > 0 {
+				if !opts.PhoneNumbers {
+					for i, recipient := range recipients {
+						recipients[i] = maskPhone(recipient)
+					}
+				}
+				args = append(args, "recipients", strings.Join(recipients, ","))
+			}
+
+			debugArgs := append([]interface{}{}, args...)
+			if message := callMessage(call); message != "" {
+				debugArgs = append(debugArgs, "message", redactMessage(message, opts.MessageBodies))
+			}
+			logger.DebugContext(ctx, "jusibe request", debugArgs...)
+
+			start := time.Now()
+			err := next(ctx, call)
+
+			args = append(args, "latency", time.Since(start))
+			if call.Response != nil {
+				args = append(args, "status", call.Response.StatusCode)
+			}
+			if messageID := callMessageID(call); messageID != "" {
+				args = append(args, "message_id", messageID)
+			}
+
+			if err != nil {
+				desc := j.redact(call, err.Error())
+				if !opts.PhoneNumbers {
+					// API error descriptions may echo the recipients back
+					desc = phonePattern.ReplaceAllStringFunc(desc, maskPhone)
+				}
+				args = append(args, "error", desc)
+				logger.ErrorContext(ctx, "jusibe call failed", args...)
+				return err
+			}
+
+			logger.InfoContext(ctx, "jusibe call", args...)
+			return nil
+		}
+	}
+}
+
+// redact removes credentials and, in QueryMode, send parameters from s
+// Transport errors quote the request URL, which holds every parameter in QueryMode
+func (j *Jusibe) redact(call *Call, s string) string {
+	if query := call.Request.URL.RawQuery; query != "" && call.Request.Method != http.MethodGet {
+		s = strings.Replace(s, query, "REDACTED", -1)
+	}
+	for _, secret := range []string{j.accessToken, j.publicKey} {
+		if secret != "" {
+			s = strings.Replace(s, secret, "REDACTED", -1)
+		}
+	}
+	return s
+}
+
+func callReference(call *Call) string {
+	switch {
+	case call.SendRequest != nil:
+		return call.SendRequest.Reference
+	case call.BulkRequest != nil:
+		return call.BulkRequest.Reference
+	}
+	return ""
+}
+
+// callRecipients returns a copy of the recipients of a send call
+func callRecipients(call *Call) []string {
+	switch {
+	case call.SendRequest != nil:
+		return []string{call.SendRequest.To}
+	case call.BulkRequest != nil:
+		return append([]string(nil), call.BulkRequest.To...)
+	}
+	return nil
+}
+
+func callMessage(call *Call) string {
+	switch {
+	case call.SendRequest != nil:
+		return call.SendRequest.Message
+	case call.BulkRequest != nil:
+		return call.BulkRequest.Message
+	}
+	return ""
+}
+
+// callMessageID returns the message id a call was made for or returned
+func callMessageID(call *Call) string {
+	if call.MessageID != "" {
+		return call.MessageID
+	}
+
+	switch result := call.Result.(type) {
+	case *SMSResponse:
+		return result.MessageID
+	case *BulkSMSResponse:
+		return result.MessageID
+	}
+	return ""
+}
+
+// phonePattern matches phone numbers in free text, e.g +2348031234567 or 08031234567
+var phonePattern = regexp.MustCompile(`\+?\b\d{7,}\b`)
+
+// maskPhone masks every digit of phone but the last four, e.g *********0000
+func maskPhone(phone string) string {
+	runes := []rune(phone)
+	for i := 0; i < len(runes)-4; i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+func redactMessage(message string, enabled bool) string {
+	if enabled {
+		return message
+	}
+	return fmt.Sprintf("[redacted %d characters]", len([]rune(message)))
+}
@@ -0,0 +1,51 @@
+//go:build go1.21
+// +build go1.21
+
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ Logger = (*slog.Logger)(nil)
+
+func TestSlogLogger(t *testing.T) {
+	t.Run("Logger should accept a *slog.Logger", func(t *testing.T) {
+		var out bytes.Buffer
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			Logger:      slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Sent", "message_id": "xyz123", "sms_credits_used": 1}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.SendSMS(context.Background(), "08031234567", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		assert.Contains(t, out.String(), `level=DEBUG msg="jusibe request"`)
+		assert.Contains(t, out.String(), `level=INFO msg="jusibe call" operation=SendSMS`)
+		assert.Contains(t, out.String(), "recipients=*******4567")
+		assert.Contains(t, out.String(), "message_id=xyz123")
+		assert.NotContains(t, out.String(), "08031234567")
+	})
+}
@@ -0,0 +1,193 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// logEntry is a log record captured by recordingLogger
+type logEntry struct {
+	level string
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recordingLogger is a Logger capturing every record
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attrs := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, attrs: attrs})
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("DEBUG", msg, args)
+}
+
+func (l *recordingLogger) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("INFO", msg, args)
+}
+
+func (l *recordingLogger) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("ERROR", msg, args)
+}
+
+// String renders every record, to check that nothing sensitive was logged
+func (l *recordingLogger) String() string {
+	var b strings.Builder
+	for _, e := range l.entries {
+		fmt.Fprintf(&b, "%s %s %v\n", e.level, e.msg, e.attrs)
+	}
+	return b.String()
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Logger should record calls with redacted values", func(t *testing.T) {
+		logger := &recordingLogger{}
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", Logger: logger}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Sent", "message_id": "xyz123", "sms_credits_used": 1}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.Send(context.Background(), &SendSMSRequest{To: "08031234567", From: "test_user", Message: "Your code is 1234", Reference: "order-42"})
+		assert.NoError(t, err)
+
+		if assert.Len(t, logger.entries, 2) {
+			debug, info := logger.entries[0], logger.entries[1]
+			assert.Equal(t, "DEBUG", debug.level)
+			assert.Equal(t, "[redacted 17 characters]", debug.attrs["message"])
+
+			assert.Equal(t, "INFO", info.level)
+			assert.Equal(t, "SendSMS", info.attrs["operation"])
+			assert.Equal(t, "/smsapi/send_sms", info.attrs["endpoint"])
+			assert.Equal(t, "order-42", info.attrs["reference"])
+			assert.Equal(t, "*******4567", info.attrs["recipients"])
+			assert.Equal(t, 200, info.attrs["status"])
+			assert.Equal(t, "xyz123", info.attrs["message_id"])
+			assert.Contains(t, info.attrs, "latency")
+		}
+
+		assert.NotContains(t, logger.String(), "08031234567")
+		assert.NotContains(t, logger.String(), "Your code")
+	})
+
+	t.Run("Logger should record errors without credentials or query parameters", func(t *testing.T) {
+		logger := &recordingLogger{}
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			Logger:      logger,
+			LogOptions:  LogOptions{PhoneNumbers: true, MessageBodies: true},
+			QueryMode:   true,
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).Return(nil, errors.New("connection refused for some_access_token"))
+
+		_, _, err = jusibe.SendSMS(context.Background(), "08031234567", "test_user", "Your code is 1234")
+		assert.Error(t, err)
+
+		if assert.Len(t, logger.entries, 2) {
+			assert.Equal(t, "Your code is 1234", logger.entries[0].attrs["message"])
+			assert.Equal(t, "08031234567", logger.entries[0].attrs["recipients"])
+
+			failure := logger.entries[1]
+			assert.Equal(t, "ERROR", failure.level)
+			assert.NotContains(t, failure.attrs, "status")
+			assert.Contains(t, failure.attrs["error"], "REDACTED")
+			assert.NotContains(t, failure.attrs["error"], "some_access_token")
+			assert.NotContains(t, failure.attrs["error"], "message=")
+		}
+	})
+
+	t.Run("Logger should record the message id of status checks", func(t *testing.T) {
+		logger := &recordingLogger{}
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", Logger: logger}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: 404}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"error": "Not found"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.CheckSMSDeliveryStatus(context.Background(), "xyz123")
+		assert.True(t, errors.Is(err, ErrNotFound))
+
+		failure := logger.entries[len(logger.entries)-1]
+		assert.Equal(t, "xyz123", failure.attrs["message_id"])
+		assert.Equal(t, 404, failure.attrs["status"])
+	})
+
+	t.Run("Logger should mask phone numbers in API error descriptions", func(t *testing.T) {
+		logger := &recordingLogger{}
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", Logger: logger}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: 400}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"error": "Invalid phone numbers: 2348031234567, +2348031234568"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.SendSMS(context.Background(), "08031234567", "test_user", "Hello World!")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2348031234567", "only logs should be masked")
+
+		failure := logger.entries[len(logger.entries)-1]
+		assert.Equal(t, "ERROR", failure.level)
+		assert.Contains(t, failure.attrs["error"], "unexpected 400 http response code")
+		assert.Contains(t, failure.attrs["error"], "*********4567, **********4568")
+		assert.NotContains(t, logger.String(), "2348031234567")
+		assert.NotContains(t, logger.String(), "2348031234568")
+	})
+
+	t.Run("maskPhone should keep the last four characters", func(t *testing.T) {
+		assert.Equal(t, "*********0000", maskPhone("2348030000000"))
+		assert.Equal(t, "123", maskPhone("123"))
+	})
+}
@@ -0,0 +1,63 @@
+package jusibe
+
+import (
+	"context"
+	"time"
+)
+
+// CallObservation is the instrumentation of a single API call
+type CallObservation struct {
+	Operation Operation
+
+	// StatusCode is the http response code of the last attempt. It is 0 when no http response was received
+	StatusCode int
+
+	// Latency is the duration of the call, including retries and rate limiting
+	Latency time.Duration
+
+	Err error
+
+	// CreditsUsed is SMSResponse.SMSCreditsUsed of successful OpSendSMS calls
+	CreditsUsed int
+
+	// Credits is the balance returned by successful OpCheckSMSCredits calls. HasCredits reports whether it is set
+	Credits    float64
+	HasCredits bool
+}
+
+// Metrics receives a CallObservation for every API call
+// It is called from the goroutines making calls, so it must be safe for concurrent use
+// See the metrics package for a Prometheus implementation
+type Metrics interface {
+	ObserveCall(ctx context.Context, o CallObservation)
+}
+
+// metricsMiddleware reports every call to m
+func metricsMiddleware(m Metrics) Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call *Call) error {
+			start := time.Now()
+			err := next(ctx, call)
+
+			o := CallObservation{Operation: call.Operation, Latency: time.Since(start), Err: err}
+			if call.Response != nil {
+				o.StatusCode = call.Response.StatusCode
+			}
+
+			if err == nil {
+				switch result := call.Result.(type) {
+				case *SMSResponse:
+					o.CreditsUsed = result.SMSCreditsUsed
+				case *SMSCreditsResponse:
+					if credits, parseErr := result.Credits(); parseErr == nil {
+						o.Credits, o.HasCredits = credits, true
+					}
+				}
+			}
+
+			m.ObserveCall(ctx, o)
+
+			return err
+		}
+	}
+}
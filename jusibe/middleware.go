@@ -0,0 +1,84 @@
+package jusibe
+
+import (
+	"context"
+	"net/http"
+)
+
+// Operation identifies a Jusibe API operation
+type Operation string
+
+const (
+	// OpSendSMS identifies SendSMS and Send
+	OpSendSMS Operation = "SendSMS"
+
+	// OpSendBulkSMS identifies SendBulkSMS and SendBulk
+	OpSendBulkSMS Operation = "SendBulkSMS"
+
+	// OpCheckSMSCredits identifies CheckSMSCredits
+	OpCheckSMSCredits Operation = "CheckSMSCredits"
+
+	// OpCheckSMSDeliveryStatus identifies CheckSMSDeliveryStatus
+	OpCheckSMSDeliveryStatus Operation = "CheckSMSDeliveryStatus"
+
+	// OpCheckBulkSMSStatus identifies CheckBulkSMSStatus
+	OpCheckBulkSMSStatus Operation = "CheckBulkSMSStatus"
+)
+
+// Call is a single API call passed through the middleware chain
+type Call struct {
+	Operation Operation
+
+	// Request is the http request. Middleware may modify it, e.g to add headers, before calling the next CallHandler
+	// It is sent once per attempt when the client retries
+	Request *http.Request
+
+	// SendRequest is the request of OpSendSMS calls. It is nil for other operations
+	SendRequest *SendSMSRequest
+
+	// BulkRequest is the request of OpSendBulkSMS calls. It is nil for other operations
+	BulkRequest *BulkSMSRequest
+
+	// MessageID is the message id of OpCheckSMSDeliveryStatus and OpCheckBulkSMSStatus calls
+	MessageID string
+
+	// Response is the *http.Response of the last attempt. It is set once the next CallHandler returns,
+	// and is nil when no http response was received
+	Response *http.Response
+
+	// Result is the response body the call decodes into: a *SMSResponse, *BulkSMSResponse, *SMSCreditsResponse,
+	// *SMSDeliveryResponse or *BulkSMSStatusResponse. It is populated once the next CallHandler returns and must not be replaced
+	Result interface{}
+}
+
+// CallHandler performs a Call
+type CallHandler func(ctx context.Context, call *Call) error
+
+// Middleware wraps a CallHandler to add behaviour before and after calls, e.g logging or signing requests
+// Middleware can also return an error without calling next, in which case no http request is made
+type Middleware func(next CallHandler) CallHandler
+
+// chain composes middleware around h. The first middleware is the outermost one
+func chain(h CallHandler, middleware []Middleware) CallHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// perform is the innermost CallHandler, which sends the request with doHTTPRequest
+func (j *Jusibe) perform(ctx context.Context, call *Call) (err error) {
+	req := call.Request
+	if req.Context() != ctx {
+		req = req.WithContext(ctx)
+	}
+
+	call.Response, err = j.doHTTPRequest(req, call.Result)
+
+	return
+}
+
+// invoke passes call through the middleware chain
+func (j *Jusibe) invoke(ctx context.Context, call *Call) error {
+	return j.handler(ctx, call)
+}
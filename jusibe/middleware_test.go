@@ -0,0 +1,134 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Run("Middleware should run in order around every call", func(t *testing.T) {
+		var trace []string
+		record := func(name string) Middleware {
+			return func(next CallHandler) CallHandler {
+				return func(ctx context.Context, call *Call) error {
+					trace = append(trace, name+" before "+string(call.Operation))
+					err := next(ctx, call)
+					trace = append(trace, name+" after "+string(call.Operation))
+					return err
+				}
+			}
+		}
+
+		correlationID := func(next CallHandler) CallHandler {
+			return func(ctx context.Context, call *Call) error {
+				if call.SendRequest != nil {
+					call.Request.Header.Set("X-Correlation-ID", call.SendRequest.Reference)
+				}
+				return next(ctx, call)
+			}
+		}
+
+		var result *SMSResponse
+		var statusCode int
+		capture := func(next CallHandler) CallHandler {
+			return func(ctx context.Context, call *Call) error {
+				err := next(ctx, call)
+				result, _ = call.Result.(*SMSResponse)
+				statusCode = call.Response.StatusCode
+				return err
+			}
+		}
+
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			Middleware:  []Middleware{record("outer"), record("inner"), correlationID, capture},
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "order-42", req.Header.Get("X-Correlation-ID"))
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Sent", "message_id": "xyz123", "sms_credits_used": 1}`)))
+			return res, nil
+		})
+
+		ssr, _, err := jusibe.Send(context.Background(), &SendSMSRequest{To: "09001000101", From: "test_user", Message: "Hello World!", Reference: "order-42"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"outer before SendSMS", "inner before SendSMS", "inner after SendSMS", "outer after SendSMS"}, trace)
+		assert.Equal(t, ssr, result, "middleware should see the decoded response")
+		assert.Equal(t, 200, statusCode)
+	})
+
+	t.Run("Middleware should be able to short-circuit calls", func(t *testing.T) {
+		blocked := errors.New("blocked")
+		var call *Call
+
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			Middleware: []Middleware{func(next CallHandler) CallHandler {
+				return func(ctx context.Context, c *Call) error {
+					call = c
+					return blocked
+				}
+			}},
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		_, res, err := jusibe.CheckSMSDeliveryStatus(context.Background(), "xyz123")
+		assert.Equal(t, blocked, err)
+		assert.Nil(t, res)
+		assert.Equal(t, OpCheckSMSDeliveryStatus, call.Operation)
+		assert.Equal(t, "xyz123", call.MessageID)
+	})
+
+	t.Run("Middleware should be able to replace the context", func(t *testing.T) {
+		type key struct{}
+
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			Middleware: []Middleware{func(next CallHandler) CallHandler {
+				return func(ctx context.Context, call *Call) error {
+					return next(context.WithValue(ctx, key{}, "value"), call)
+				}
+			}},
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "value", req.Context().Value(key{}))
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"sms_credits": "100"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.CheckSMSCredits(context.Background())
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,84 @@
+package jusibe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeLayout is the layout of timestamps returned by Jusibe, e.g 2015-05-19 04:34:48
+const TimeLayout = "2006-01-02 15:04:05"
+
+// defaultLocation is the timezone Jusibe timestamps are in
+// Nigeria doesn't observe daylight saving time, so a fixed WAT zone is used when the tz database isn't available
+var defaultLocation = loadLocation("Africa/Lagos", time.FixedZone("WAT", 60*60))
+
+func loadLocation(name string, fallback *time.Location) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// ParseTime parses a Jusibe timestamp in the loc timezone. It uses Africa/Lagos when loc is nil
+// Empty, null and all-zero timestamps parse to the zero time.Time without an error
+func ParseTime(value string, loc *time.Location) (t time.Time, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "null" || strings.HasPrefix(value, "0000-00-00") {
+		return
+	}
+
+	if loc == nil {
+		loc = defaultLocation
+	}
+
+	t, err = time.ParseInLocation(TimeLayout, value, loc)
+	if err != nil {
+		err = fmt.Errorf("invalid Jusibe timestamp %q: %s", value, err)
+	}
+
+	return
+}
+
+// FormatTime formats t as a Jusibe timestamp in the loc timezone. It uses Africa/Lagos when loc is nil
+// It is the inverse of ParseTime, e.g for fakes of the Jusibe API
+func FormatTime(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = defaultLocation
+	}
+	return t.In(loc).Format(TimeLayout)
+}
+
+// ParseCount parses a quoted Jusibe count such as "2"
+// Empty and null counts parse to zero without an error
+func ParseCount(value string) (n int, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "null" {
+		return
+	}
+
+	n, err = strconv.Atoi(value)
+	if err != nil {
+		err = fmt.Errorf("invalid Jusibe count %q: %s", value, err)
+	}
+
+	return
+}
+
+// ParseCredits parses a quoted Jusibe credit balance, which may be a decimal such as "100.50"
+// Empty and null balances parse to zero without an error
+func ParseCredits(value string) (credits float64, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "null" {
+		return
+	}
+
+	credits, err = strconv.ParseFloat(strings.Replace(value, ",", "", -1), 64)
+	if err != nil {
+		err = fmt.Errorf("invalid Jusibe credits %q: %s", value, err)
+	}
+
+	return
+}
@@ -0,0 +1,129 @@
+package jusibe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a client side token bucket shared by every goroutine using the client
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which the bucket refills
+	RequestsPerSecond float64
+
+	// Burst is the bucket size, i.e the number of requests allowed at once. Defaults to 1
+	Burst int
+
+	// OnWait is called with the time a request waited for the limiter, which is useful to observe back-pressure
+	// It is called for every request, including those which didn't wait
+	OnWait func(ctx context.Context, wait time.Duration)
+}
+
+func (rl *RateLimit) validate() (err error) {
+	if rl.RequestsPerSecond <= 0 {
+		err = errors.New("invalid RateLimit: RequestsPerSecond must be greater than zero")
+	} else if rl.Burst < 0 {
+		err = errors.New("invalid RateLimit: Burst must not be negative")
+	}
+	return
+}
+
+// rateLimiter is a token bucket rate limiter
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	onWait func(ctx context.Context, wait time.Duration)
+	now    func() time.Time
+}
+
+func newRateLimiter(rl *RateLimit) *rateLimiter {
+	burst := float64(rl.Burst)
+	if burst == 0 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rl.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		onWait: rl.OnWait,
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller must wait before using it
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	// Tokens may go negative, which queues callers behind each other
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token to the bucket
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.mu.Unlock()
+}
+
+// wait blocks until a token is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) (err error) {
+	if l == nil {
+		return
+	}
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	wait := l.reserve()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			l.cancel()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if l.onWait != nil {
+		l.onWait(ctx, wait)
+	}
+
+	return
+}
+
+// rateLimiterFor returns the limiter which applies to req
+// Sends are POST requests while status and credit checks are GET requests
+func (j *Jusibe) rateLimiterFor(req *http.Request) *rateLimiter {
+	if req.Method == http.MethodGet {
+		return j.statusRateLimiter
+	}
+	return j.sendRateLimiter
+}
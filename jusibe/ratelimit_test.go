@@ -0,0 +1,96 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("New should validate RateLimit", func(t *testing.T) {
+		_, err := New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key", SendRateLimit: &RateLimit{}})
+		assert.Error(t, err)
+
+		_, err = New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key", StatusRateLimit: &RateLimit{RequestsPerSecond: 1, Burst: -1}})
+		assert.Error(t, err)
+	})
+
+	t.Run("reserve should allow burst then space requests by rate", func(t *testing.T) {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 2, Burst: 2})
+		limiter.now = func() time.Time { return now }
+
+		assert.Equal(t, time.Duration(0), limiter.reserve())
+		assert.Equal(t, time.Duration(0), limiter.reserve())
+		assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+		assert.Equal(t, time.Second, limiter.reserve())
+
+		now = now.Add(10 * time.Second)
+		assert.Equal(t, time.Duration(0), limiter.reserve(), "should refill up to burst")
+		assert.Equal(t, time.Duration(0), limiter.reserve())
+		assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+	})
+
+	t.Run("wait should respect context", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 0.001})
+
+		assert.NoError(t, limiter.wait(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, limiter.wait(ctx))
+	})
+
+	t.Run("limiters should be selected per endpoint and report wait time", func(t *testing.T) {
+		var mu sync.Mutex
+		var statusWaits, sendWaits int
+		cfg := &Config{
+			AccessToken: "some_access_token",
+			PublicKey:   "some_public_key",
+			SendRateLimit: &RateLimit{RequestsPerSecond: 1000, OnWait: func(ctx context.Context, wait time.Duration) {
+				mu.Lock()
+				sendWaits++
+				mu.Unlock()
+			}},
+			StatusRateLimit: &RateLimit{RequestsPerSecond: 1000, Burst: 5, OnWait: func(ctx context.Context, wait time.Duration) {
+				mu.Lock()
+				statusWaits++
+				mu.Unlock()
+			}},
+		}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Times(10).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: http.StatusOK}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"sms_credits": "100"}`)))
+			return res, nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, err := jusibe.CheckSMSCredits(context.Background())
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 10, statusWaits)
+		assert.Equal(t, 0, sendWaits)
+	})
+}
@@ -0,0 +1,224 @@
+package jusibe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/azeezolaniran2016/jusibe-go/segment"
+)
+
+// SendSMSRequest is a request to send an SMS to a single recipient with Send
+type SendSMSRequest struct {
+	// To is the recipient phone number
+	To string
+
+	// From is the SenderID the SMS is sent from. See ValidateSenderID for the rules it must follow
+	From string
+
+	// Message is the SMS body
+	Message string
+
+	// Reference is a caller defined identifier used to correlate the request, e.g in logs. It is available to Middleware
+	// It is not sent to Jusibe
+	Reference string
+
+	// MaxSegments, when greater than zero, overrides Config.MaxSegments for this request
+	MaxSegments int
+}
+
+// Validate checks the request before any http request is made
+func (r *SendSMSRequest) Validate() (err error) {
+	if err = recipientIsValid(r.To); err != nil {
+		return
+	}
+
+	if err = ValidateSenderID(r.From); err != nil {
+		return
+	}
+
+	err = messageIsValid(r.Message, r.MaxSegments)
+
+	return
+}
+
+// BulkSMSRequest is a request to send the same SMS to many recipients with SendBulk
+type BulkSMSRequest struct {
+	// To is the list of recipient phone numbers
+	To []string
+
+	// From is the SenderID the SMS is sent from. See ValidateSenderID for the rules it must follow
+	From string
+
+	// Message is the SMS body
+	Message string
+
+	// Reference is a caller defined identifier used to correlate the request, e.g in logs. It is available to Middleware
+	// It is not sent to Jusibe
+	Reference string
+
+	// MaxSegments, when greater than zero, overrides Config.MaxSegments for this request
+	MaxSegments int
+}
+
+// Validate checks the request before any http request is made
+func (r *BulkSMSRequest) Validate() (err error) {
+	if len(r.To) == 0 {
+		return &RecipientError{Err: errors.New("at least one recipient is required")}
+	}
+
+	for _, to := range r.To {
+		if err = recipientIsValid(to); err != nil {
+			return
+		}
+	}
+
+	if err = ValidateSenderID(r.From); err != nil {
+		return
+	}
+
+	err = messageIsValid(r.Message, r.MaxSegments)
+
+	return
+}
+
+// recipientIsValid checks a single recipient
+func recipientIsValid(to string) (err error) {
+	switch {
+	case strings.TrimSpace(to) == "":
+		err = &RecipientError{Recipient: to, Err: errors.New("recipient is required")}
+	case strings.Contains(to, ","):
+		err = &RecipientError{Recipient: to, Err: errors.New("comma separated recipients must be sent as a BulkSMSRequest")}
+	}
+	return
+}
+
+// messageIsValid checks that message is not empty and, when maxSegments is greater than zero, fits in maxSegments SMS parts
+func messageIsValid(message string, maxSegments int) (err error) {
+	if message == "" {
+		return ErrEmptyMessage
+	}
+
+	if maxSegments <= 0 {
+		return
+	}
+
+	if e := segment.Calculate(message); e.Segments > maxSegments {
+		err = fmt.Errorf("%w: %d %s parts exceed the limit of %d", ErrMessageTooLong, e.Segments, e.Encoding, maxSegments)
+	}
+
+	return
+}
+
+// SplitRecipients splits a comma separated list of recipients, trimming spaces and skipping empty entries
+func SplitRecipients(to string) (recipients []string) {
+	for _, recipient := range strings.Split(to, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return
+}
+
+// normalizeRecipients applies the NormalizeRecipient hook to every recipient
+// It returns the recipients joined with commas, as expected by Jusibe
+func (j *Jusibe) normalizeRecipients(recipients ...string) (string, error) {
+	if j.normalizeRecipient == nil {
+		return strings.Join(recipients, ","), nil
+	}
+
+	normalized := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		n, err := j.normalizeRecipient(strings.TrimSpace(recipient))
+		if err != nil {
+			return "", &RecipientError{Recipient: recipient, Err: err}
+		}
+		normalized[i] = n
+	}
+
+	return strings.Join(normalized, ","), nil
+}
+
+// Send sends r to the /send_sms endpoint
+// It also returns a *http.Response for convinience to its caller, along with a *SMSResponse and error
+func (j *Jusibe) Send(ctx context.Context, r *SendSMSRequest) (ssr *SMSResponse, res *http.Response, err error) {
+	req := *r
+	if req.MaxSegments <= 0 {
+		req.MaxSegments = j.maxSegments
+	}
+
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	if err = j.senderIDs.check(req.From); err != nil {
+		return
+	}
+
+	to, err := j.normalizeRecipients(req.To)
+	if err != nil {
+		return
+	}
+
+	endpoint := "/send_sms"
+	params := url.Values{"to": {to}, "from": {req.From}, "message": {req.Message}}
+
+	httpReq, err := j.createHTTPRequest(ctx, http.MethodPost, endpoint, params)
+	if err != nil {
+		return
+	}
+
+	ssr = new(SMSResponse)
+	call := &Call{Operation: OpSendSMS, Request: httpReq, SendRequest: &req, Result: ssr}
+	err = j.invoke(ctx, call)
+	res = call.Response
+
+	return
+}
+
+// SendBulk sends r to the /bulk/send_sms endpoint
+// It also returns a *http.Response for convinience to its caller, along with a *BulkSMSResponse and error
+func (j *Jusibe) SendBulk(ctx context.Context, r *BulkSMSRequest) (bsr *BulkSMSResponse, res *http.Response, err error) {
+	return j.sendBulk(ctx, r, true)
+}
+
+// sendBulk sends r, applying Config.NormalizeRecipient to its recipients when normalize is true
+func (j *Jusibe) sendBulk(ctx context.Context, r *BulkSMSRequest, normalize bool) (bsr *BulkSMSResponse, res *http.Response, err error) {
+	req := *r
+	if req.MaxSegments <= 0 {
+		req.MaxSegments = j.maxSegments
+	}
+
+	if err = req.Validate(); err != nil {
+		return
+	}
+
+	if err = j.senderIDs.check(req.From); err != nil {
+		return
+	}
+
+	to := strings.Join(req.To, ",")
+	if normalize {
+		if to, err = j.normalizeRecipients(req.To...); err != nil {
+			return
+		}
+	}
+
+	endpoint := "/bulk/send_sms"
+	params := url.Values{"to": {to}, "from": {req.From}, "message": {req.Message}}
+
+	httpReq, err := j.createHTTPRequest(ctx, http.MethodPost, endpoint, params)
+	if err != nil {
+		return
+	}
+
+	bsr = new(BulkSMSResponse)
+	call := &Call{Operation: OpSendBulkSMS, Request: httpReq, BulkRequest: &req, Result: bsr}
+	err = j.invoke(ctx, call)
+	res = call.Response
+
+	return
+}
@@ -0,0 +1,167 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendRequest(t *testing.T) {
+	t.Run("SendSMSRequest.Validate", func(t *testing.T) {
+		valid := SendSMSRequest{To: "09001000101", From: "test_user", Message: "Hello World!"}
+		assert.NoError(t, valid.Validate())
+
+		r := valid
+		r.To = " "
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidRecipient))
+
+		r = valid
+		r.To = "09001000101,08030000000"
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidRecipient), "should refuse comma separated recipients")
+
+		r = valid
+		r.From = ""
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidSender))
+
+		r = valid
+		r.From = "a_long_sender_id"
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidSender))
+
+		r = valid
+		r.Message = ""
+		assert.True(t, errors.Is(r.Validate(), ErrEmptyMessage))
+
+		r = valid
+		r.Message, r.MaxSegments = strings.Repeat("a", 161), 1
+		assert.EqualError(t, r.Validate(), "jusibe: message too long: 2 GSM-7 parts exceed the limit of 1")
+	})
+
+	t.Run("BulkSMSRequest.Validate", func(t *testing.T) {
+		valid := BulkSMSRequest{To: []string{"09001000101", "08030000000"}, From: "test_user", Message: "Hello World!"}
+		assert.NoError(t, valid.Validate())
+
+		r := valid
+		r.To = nil
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidRecipient))
+
+		r = valid
+		r.To = []string{"09001000101", ""}
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidRecipient))
+
+		r = valid
+		r.From = "a_long_sender_id"
+		assert.True(t, errors.Is(r.Validate(), ErrInvalidSender))
+
+		r = valid
+		r.Message = ""
+		assert.True(t, errors.Is(r.Validate(), ErrEmptyMessage))
+	})
+
+	t.Run("Send should not make a request when validation fails", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key"}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		_, res, err := jusibe.Send(context.Background(), &SendSMSRequest{To: "09001000101", From: "test_user"})
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrEmptyMessage))
+
+		_, res, err = jusibe.SendBulk(context.Background(), &BulkSMSRequest{From: "test_user", Message: "Hello World!"})
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrInvalidRecipient))
+	})
+
+	t.Run("SendBulk should join recipients", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", MaxSegments: 1}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "/smsapi/bulk/send_sms", req.URL.Path)
+			assert.Equal(t, "09001000101,08030000000", req.FormValue("to"))
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Submitted", "bulk_message_id": "xeqd6rs3d26"}`)))
+			return res, nil
+		})
+
+		r := &BulkSMSRequest{
+			To:          []string{"09001000101", "08030000000"},
+			From:        "test_user",
+			Message:     strings.Repeat("a", 161),
+			Reference:   "campaign-42",
+			MaxSegments: 2,
+		}
+
+		bs, _, err := jusibe.SendBulk(context.Background(), r)
+		assert.NoError(t, err, "request MaxSegments should override Config.MaxSegments")
+		assert.Equal(t, "xeqd6rs3d26", bs.MessageID)
+	})
+
+	t.Run("Send should escape special characters in either mode", func(t *testing.T) {
+		message := "50% off & free delivery #deals + more"
+
+		for _, queryMode := range []bool{false, true} {
+			cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", QueryMode: queryMode}
+
+			mockController := gomock.NewController(t)
+			mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+			jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+			assert.NoError(t, err)
+
+			mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Empty(t, req.URL.Fragment)
+				if queryMode {
+					assert.Nil(t, req.Body)
+					assert.Equal(t, message, req.URL.Query().Get("message"))
+				} else {
+					assert.Empty(t, req.URL.RawQuery)
+					assert.Equal(t, message, req.FormValue("message"))
+				}
+				res := &http.Response{StatusCode: 200}
+				res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Sent", "message_id": "xyz123", "sms_credits_used": 1}`)))
+				return res, nil
+			})
+
+			_, _, err = jusibe.SendSMS(context.Background(), "09001000101", "test_user", message)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("CheckSMSDeliveryStatus should escape the message id", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key"}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.AssignableToTypeOf(&http.Request{})).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "https://jusibe.com/smsapi/delivery_status?message_id=a%26b%23c", req.URL.String())
+			res := &http.Response{StatusCode: 200}
+			res.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"status": "Delivered", "message_id": "a&b#c"}`)))
+			return res, nil
+		})
+
+		_, _, err = jusibe.CheckSMSDeliveryStatus(context.Background(), "a&b#c")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,187 @@
+package jusibe
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = (time.Millisecond * 500)
+	defaultRetryMaxBackoff     = (time.Second * 10)
+	defaultRetryMultiplier     = 2.0
+	defaultRetryJitter         = 0.2
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures automatic retries of failed requests
+// Requests are retried on network errors and on RetryableStatusCodes
+// Reads (CheckSMSCredits, CheckSMSDeliveryStatus, CheckBulkSMSStatus) are idempotent and always retried
+// Sends (SendSMS, SendBulkSMS) are only retried when RetrySends is true, because a retry may deliver the SMS twice
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Defaults to 3
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. Defaults to 500ms
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the computed backoff. Defaults to 10s
+	// Responses with a Retry-After longer than MaxBackoff are returned without being retried
+	MaxBackoff time.Duration
+
+	// Multiplier grows the backoff after every attempt. Defaults to 2
+	Multiplier float64
+
+	// Jitter is the fraction (0 to 1) of the backoff which is randomized. Zero disables jitter
+	Jitter float64
+
+	// RetryableStatusCodes are the http response codes which are retried. Defaults to 429, 500, 502, 503 and 504
+	RetryableStatusCodes []int
+
+	// RetrySends opts SendSMS and SendBulkSMS into retries
+	RetrySends bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with the default values set and a jitter of 0.2
+func DefaultRetryPolicy() *RetryPolicy {
+	return (&RetryPolicy{Jitter: defaultRetryJitter}).withDefaults()
+}
+
+// withDefaults returns a copy of the policy with zero fields set to their default value
+func (p *RetryPolicy) withDefaults() *RetryPolicy {
+	cp := *p
+	if cp.MaxAttempts == 0 {
+		cp.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if cp.InitialBackoff == 0 {
+		cp.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if cp.MaxBackoff == 0 {
+		cp.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if cp.Multiplier == 0 {
+		cp.Multiplier = defaultRetryMultiplier
+	}
+	if cp.RetryableStatusCodes == nil {
+		cp.RetryableStatusCodes = defaultRetryableStatusCodes
+	} else {
+		cp.RetryableStatusCodes = append([]int(nil), cp.RetryableStatusCodes...)
+	}
+	return &cp
+}
+
+func (p *RetryPolicy) validate() (err error) {
+	if p.MaxAttempts < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.Multiplier < 1 {
+		err = errors.New("invalid RetryPolicy: MaxAttempts, InitialBackoff and MaxBackoff must not be negative and Multiplier must be at least 1")
+	} else if p.Jitter < 0 || p.Jitter > 1 {
+		err = errors.New("invalid RetryPolicy: Jitter must be between 0 and 1")
+	}
+	return
+}
+
+// attemptsFor returns the number of attempts allowed for req
+func (p *RetryPolicy) attemptsFor(req *http.Request) int {
+	if p == nil || (req.Method != http.MethodGet && !p.RetrySends) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether the outcome of an attempt is transient
+func (p *RetryPolicy) shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, code := range p.RetryableStatusCodes {
+			if apiErr.StatusCode == code {
+				return !p.retryAfterExceedsMaxBackoff(res)
+			}
+		}
+		return false
+	}
+
+	// A nil response means the request never got an answer, e.g a timeout or connection reset
+	return err != nil && res == nil
+}
+
+// retryAfterExceedsMaxBackoff reports whether res asks for a longer wait than MaxBackoff
+// Such responses are returned to the caller rather than blocking the call until its context is done
+func (p *RetryPolicy) retryAfterExceedsMaxBackoff(res *http.Response) bool {
+	if res == nil {
+		return false
+	}
+	d, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	return ok && d > p.MaxBackoff
+}
+
+// backoff returns the wait before the next attempt
+// It honours the Retry-After header when Jusibe sends one, up to MaxBackoff
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			if d > p.MaxBackoff {
+				d = p.MaxBackoff
+			}
+			return d
+		}
+	}
+
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d -= d * p.Jitter * rand.Float64()
+
+	return time.Duration(d)
+}
+
+// wait blocks for the backoff of attempt or until ctx is done
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, res *http.Response) error {
+	timer := time.NewTimer(p.backoff(attempt, res))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value in either delay-seconds or http-date form
+func parseRetryAfter(value string, now time.Time) (d time.Duration, ok bool) {
+	if value == "" {
+		return
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d = date.Sub(now); d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return
+}
@@ -0,0 +1,188 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	newResponse := func(statusCode int, body string) *http.Response {
+		res := &http.Response{StatusCode: statusCode, Header: http.Header{}}
+		res.Body = ioutil.NopCloser(bytes.NewReader([]byte(body)))
+		return res
+	}
+
+	fastPolicy := func() *RetryPolicy {
+		return &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	}
+
+	t.Run("New should validate RetryPolicy", func(t *testing.T) {
+		_, err := New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: &RetryPolicy{Jitter: 2}})
+		assert.Error(t, err)
+
+		jusibe, err := New(&Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: &RetryPolicy{}})
+		assert.NoError(t, err)
+		assert.Equal(t, defaultRetryMaxAttempts, jusibe.retryPolicy.MaxAttempts, "should set default RetryPolicy values")
+		assert.Equal(t, defaultRetryableStatusCodes, jusibe.retryPolicy.RetryableStatusCodes, "should set default RetryPolicy values")
+	})
+
+	t.Run("reads should be retried on retryable status codes", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: fastPolicy()}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		gomock.InOrder(
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(newResponse(http.StatusServiceUnavailable, ""), nil),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(nil, errors.New("connection reset")),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(newResponse(http.StatusOK, `{"sms_credits": "100"}`), nil),
+		)
+
+		sc, res, err := jusibe.CheckSMSCredits(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "100", sc.SMSCredits)
+	})
+
+	t.Run("reads should stop after MaxAttempts", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: fastPolicy()}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Times(3).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			return newResponse(http.StatusBadGateway, ""), nil
+		})
+
+		_, _, err = jusibe.CheckSMSDeliveryStatus(context.Background(), "xyz123")
+		assert.True(t, errors.Is(err, ErrServer))
+	})
+
+	t.Run("non retryable status codes should not be retried", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: fastPolicy()}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Times(1).Return(newResponse(http.StatusUnauthorized, ""), nil)
+
+		_, _, err = jusibe.CheckSMSCredits(context.Background())
+		assert.True(t, errors.Is(err, ErrUnauthorized))
+	})
+
+	t.Run("sends should only be retried when RetrySends is set", func(t *testing.T) {
+		policy := fastPolicy()
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: policy}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Times(1).Return(newResponse(http.StatusServiceUnavailable, ""), nil)
+
+		_, _, err = jusibe.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.Error(t, err)
+
+		policy.RetrySends = true
+		jusibe, err = NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		gomock.InOrder(
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(newResponse(http.StatusServiceUnavailable, ""), nil),
+			mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Return(newResponse(http.StatusOK, `{"status": "Sent", "message_id": "xyz123", "sms_credits_used": 1}`), nil),
+		)
+
+		s, _, err := jusibe.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.Equal(t, "xyz123", s.MessageID)
+	})
+
+	t.Run("retries should stop when context is done", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: &RetryPolicy{InitialBackoff: time.Hour}}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).Times(1).Return(newResponse(http.StatusServiceUnavailable, ""), nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, _, err = jusibe.CheckSMSCredits(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("backoff should honour Retry-After", func(t *testing.T) {
+		policy := DefaultRetryPolicy()
+
+		res := newResponse(http.StatusTooManyRequests, "")
+		res.Header.Set("Retry-After", "7")
+		assert.Equal(t, 7*time.Second, policy.backoff(1, res))
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		d, ok := parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, d)
+
+		_, ok = parseRetryAfter("soon", now)
+		assert.False(t, ok)
+
+		res.Header.Set("Retry-After", "86400")
+		assert.Equal(t, policy.MaxBackoff, policy.backoff(1, res), "Retry-After should be capped by MaxBackoff")
+	})
+
+	t.Run("Retry-After beyond MaxBackoff should not be retried", func(t *testing.T) {
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", RetryPolicy: fastPolicy()}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		mockRoundTripper.EXPECT().RoundTrip(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			res := newResponse(http.StatusTooManyRequests, `{"error": "Too many requests"}`)
+			res.Header.Set("Retry-After", "86400")
+			return res, nil
+		}).Times(1)
+
+		start := time.Now()
+		_, res, err := jusibe.CheckSMSCredits(context.Background())
+
+		assert.True(t, errors.Is(err, ErrRateLimited))
+		assert.Equal(t, "86400", res.Header.Get("Retry-After"))
+		assert.True(t, time.Since(start) < time.Second, "should not wait for Retry-After")
+	})
+
+	t.Run("backoff should grow exponentially up to MaxBackoff", func(t *testing.T) {
+		policy := (&RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}).withDefaults()
+
+		assert.Equal(t, time.Second, policy.backoff(1, nil))
+		assert.Equal(t, 2*time.Second, policy.backoff(2, nil))
+		assert.Equal(t, 3*time.Second, policy.backoff(3, nil))
+	})
+}
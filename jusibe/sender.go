@@ -0,0 +1,176 @@
+package jusibe
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxSenderIDLength is the maximum length of a SenderID, as defined in Jusibe API docs
+const maxSenderIDLength = 11
+
+// ErrSenderIDNotAllowed is wrapped by a *SenderIDError when a SenderID is missing from Config.SenderIDs
+var ErrSenderIDNotAllowed = errors.New("jusibe: sender id not allowed")
+
+// SenderIDError is returned when a SenderID fails validation or is not allowed by Config.SenderIDs, before any http request is made
+// It matches ErrInvalidSender with errors.Is and unwraps to the underlying error
+type SenderIDError struct {
+	SenderID string
+	Err      error
+}
+
+// Error implements the error interface
+func (e *SenderIDError) Error() string {
+	return fmt.Sprintf("invalid sender id %q: %s", e.SenderID, e.Err)
+}
+
+// Is reports whether target is ErrInvalidSender
+func (e *SenderIDError) Is(target error) bool {
+	return target == ErrInvalidSender
+}
+
+// Unwrap returns the underlying error
+func (e *SenderIDError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateSenderID checks from against the SenderID rules of Jusibe and Nigerian carriers
+//
+// Numeric SenderIDs are made of digits with an optional leading +, and allow at most 11 characters
+// Alphanumeric SenderIDs allow at most 11 characters, which must be ASCII letters, digits, spaces or - _ . &
+// They must contain a letter and must not start or end with a space
+func ValidateSenderID(from string) error {
+	if reason := senderIDRule(from); reason != "" {
+		return &SenderIDError{SenderID: from, Err: errors.New(reason)}
+	}
+	return nil
+}
+
+// senderIDRule returns the first SenderID rule from breaks, or an empty string when from is valid
+func senderIDRule(from string) string {
+	if from == "" {
+		return "sender id is required"
+	}
+
+	if digits := strings.TrimPrefix(from, "+"); isDigits(digits) {
+		if len(from) > maxSenderIDLength {
+			return fmt.Sprintf("numeric sender ids allow at most %d characters, got %d", maxSenderIDLength, len(from))
+		}
+		return ""
+	}
+
+	// Characters are counted rather than bytes, so that a non-ASCII character is reported as such instead of as a length overflow
+	if n := utf8.RuneCountInString(from); n > maxSenderIDLength {
+		return fmt.Sprintf("alphanumeric sender ids allow at most %d characters, got %d", maxSenderIDLength, n)
+	}
+
+	hasLetter := false
+	for _, r := range from {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			hasLetter = true
+		case (r >= '0' && r <= '9') || r == ' ' || r == '-' || r == '_' || r == '.' || r == '&':
+		default:
+			return fmt.Sprintf("unsupported character %q", r)
+		}
+	}
+
+	switch {
+	case !hasLetter:
+		return "alphanumeric sender ids must contain a letter"
+	case strings.HasPrefix(from, " ") || strings.HasSuffix(from, " "):
+		return "sender ids must not start or end with a space"
+	}
+
+	return ""
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SenderIDRegistry is an allowlist of SenderIDs approved for an account
+// SenderIDs are matched case-insensitively. It is safe for concurrent use, so it can be updated while the client is in use
+// The zero value is an empty registry ready to use
+type SenderIDRegistry struct {
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// NewSenderIDRegistry creates a SenderIDRegistry allowing ids
+// It returns an error when any of ids fails ValidateSenderID
+func NewSenderIDRegistry(ids ...string) (r *SenderIDRegistry, err error) {
+	r = &SenderIDRegistry{ids: map[string]string{}}
+	for _, id := range ids {
+		if err = r.Add(id); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// Add allows id. It returns an error when id fails ValidateSenderID
+func (r *SenderIDRegistry) Add(id string) error {
+	if err := ValidateSenderID(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ids == nil {
+		r.ids = map[string]string{}
+	}
+	r.ids[strings.ToLower(id)] = id
+	return nil
+}
+
+// Remove disallows id
+func (r *SenderIDRegistry) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.ids, strings.ToLower(id))
+}
+
+// Allowed reports whether id is in the registry
+func (r *SenderIDRegistry) Allowed(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.ids[strings.ToLower(id)]
+	return ok
+}
+
+// IDs returns the allowed SenderIDs, as they were added, in sorted order
+func (r *SenderIDRegistry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.ids))
+	for _, id := range r.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// check returns a *SenderIDError wrapping ErrSenderIDNotAllowed when from is not allowed
+// A nil registry allows every SenderID
+func (r *SenderIDRegistry) check(from string) error {
+	if r == nil || r.Allowed(from) {
+		return nil
+	}
+	return &SenderIDError{SenderID: from, Err: ErrSenderIDNotAllowed}
+}
@@ -0,0 +1,90 @@
+package jusibe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSenderID(t *testing.T) {
+	t.Run("ValidateSenderID should accept valid sender ids", func(t *testing.T) {
+		for _, from := range []string{"Azeez", "test_user", "MY-SHOP.NG", "A&B Stores", "08030000000", "+2348030000", "12345678901"} {
+			assert.NoError(t, ValidateSenderID(from), from)
+		}
+	})
+
+	t.Run("ValidateSenderID should reject invalid sender ids", func(t *testing.T) {
+		for from, reason := range map[string]string{
+			"":             "sender id is required",
+			"LongSenderID": "alphanumeric sender ids allow at most 11 characters, got 12",
+			"Café":         `unsupported character 'é'`,
+			"Café Lagos!":  `unsupported character 'é'`,
+			"Hi!":          `unsupported character '!'`,
+			"12345-678":    "alphanumeric sender ids must contain a letter",
+			" Azeez":       "sender ids must not start or end with a space",
+			"123456789012": "numeric sender ids allow at most 11 characters, got 12",
+			"+23480300000": "numeric sender ids allow at most 11 characters, got 12",
+		} {
+			err := ValidateSenderID(from)
+			assert.True(t, errors.Is(err, ErrInvalidSender), from)
+
+			var senderErr *SenderIDError
+			if assert.True(t, errors.As(err, &senderErr), from) {
+				assert.Equal(t, from, senderErr.SenderID)
+				assert.EqualError(t, senderErr.Err, reason, from)
+			}
+		}
+	})
+
+	t.Run("SenderIDRegistry should match sender ids case-insensitively", func(t *testing.T) {
+		_, err := NewSenderIDRegistry("Azeez", "Café")
+		assert.True(t, errors.Is(err, ErrInvalidSender))
+
+		r, err := NewSenderIDRegistry("Azeez", "MyShop")
+		assert.NoError(t, err)
+
+		assert.True(t, r.Allowed("azeez"))
+		assert.False(t, r.Allowed("Other"))
+		assert.Equal(t, []string{"Azeez", "MyShop"}, r.IDs())
+
+		r.Remove("MYSHOP")
+		assert.False(t, r.Allowed("MyShop"))
+	})
+
+	t.Run("zero value SenderIDRegistry should be usable", func(t *testing.T) {
+		r := &SenderIDRegistry{}
+		assert.False(t, r.Allowed("Azeez"))
+		assert.Empty(t, r.IDs())
+		r.Remove("Azeez")
+
+		assert.NoError(t, r.Add("Azeez"))
+		assert.True(t, r.Allowed("AZEEZ"))
+	})
+
+	t.Run("Send should refuse sender ids missing from Config.SenderIDs", func(t *testing.T) {
+		senderIDs, err := NewSenderIDRegistry("Azeez")
+		assert.NoError(t, err)
+
+		cfg := &Config{AccessToken: "some_access_token", PublicKey: "some_public_key", SenderIDs: senderIDs}
+
+		mockController := gomock.NewController(t)
+		mockRoundTripper := mocks.NewMockRoundTripper(mockController)
+
+		jusibe, err := NewWithHTTPClient(cfg, &http.Client{Transport: mockRoundTripper})
+		assert.NoError(t, err)
+
+		_, res, err := jusibe.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrInvalidSender))
+		assert.True(t, errors.Is(err, ErrSenderIDNotAllowed))
+		assert.EqualError(t, err, `invalid sender id "test_user": jusibe: sender id not allowed`)
+
+		_, _, err = jusibe.SendBulkSMS(context.Background(), "09001000101", "Other", "Hello World!")
+		assert.True(t, errors.Is(err, ErrSenderIDNotAllowed))
+	})
+}
@@ -1,38 +1,142 @@
 package jusibe
 
-type smsDeliveryStatus string
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DeliveryStatus is the status of an SMS or Bulk SMS as reported by Jusibe
+// Values which aren't one of the Status* constants are preserved as returned by Jusibe, see IsKnown
+type DeliveryStatus string
 
 const (
+	// StatusUnknown is the zero DeliveryStatus
+	// This indicates that Jusibe returned an empty or null status
+	StatusUnknown DeliveryStatus = ""
+
 	// StatusSMSRejected is delivery status for rejected SMS
 	// This indicates that the SMS wasn't sent
-	StatusSMSRejected smsDeliveryStatus = "Rejected"
+	StatusSMSRejected DeliveryStatus = "Rejected"
 
 	// StatusSMSSent is delivery status for send SMS
 	// This indicates that the SMS was successfully sent but the receipient is yet to receive the SMS
-	StatusSMSSent smsDeliveryStatus = "Sent"
+	StatusSMSSent DeliveryStatus = "Sent"
 
 	// StatusSMSDelivered is delivery status for delivered SMS
 	// This indicates that the receipient received the SMS
-	StatusSMSDelivered smsDeliveryStatus = "Delivered"
+	StatusSMSDelivered DeliveryStatus = "Delivered"
 
 	// StatusBulkSMSSubmitted is submitted status for Submitted Bulk SMS
 	// This indicates that the bulk sms was successfully submitted to the api
-	StatusBulkSMSSubmitted smsDeliveryStatus = "Submitted"
+	StatusBulkSMSSubmitted DeliveryStatus = "Submitted"
+
+	// StatusBulkSMSProcessing is status for Bulk SMS which Jusibe is sending out
+	StatusBulkSMSProcessing DeliveryStatus = "Processing"
+
+	// StatusBulkSMSCompleted is status for Bulk SMS which Jusibe has finished sending out
+	StatusBulkSMSCompleted DeliveryStatus = "Completed"
+
+	// StatusBulkSMSFailed is status for Bulk SMS which Jusibe failed to send out
+	StatusBulkSMSFailed DeliveryStatus = "Failed"
 )
 
+var knownDeliveryStatuses = []DeliveryStatus{
+	StatusSMSRejected,
+	StatusSMSSent,
+	StatusSMSDelivered,
+	StatusBulkSMSSubmitted,
+	StatusBulkSMSProcessing,
+	StatusBulkSMSCompleted,
+	StatusBulkSMSFailed,
+}
+
+// ParseDeliveryStatus parses a status returned by Jusibe
+// Known statuses are matched case insensitively, unknown ones are returned as is
+func ParseDeliveryStatus(s string) DeliveryStatus {
+	s = strings.TrimSpace(s)
+	for _, status := range knownDeliveryStatuses {
+		if strings.EqualFold(s, string(status)) {
+			return status
+		}
+	}
+	return DeliveryStatus(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+// A null status decodes to StatusUnknown
+func (ds *DeliveryStatus) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*ds = StatusUnknown
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*ds = ParseDeliveryStatus(s)
+
+	return nil
+}
+
+// String returns the status as returned by Jusibe
+func (ds DeliveryStatus) String() string {
+	return string(ds)
+}
+
+// IsKnown reports whether the status is one of the Status* constants, other than StatusUnknown
+func (ds DeliveryStatus) IsKnown() bool {
+	for _, status := range knownDeliveryStatuses {
+		if ds == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether the status will not change anymore
+func (ds DeliveryStatus) IsTerminal() bool {
+	switch ds {
+	case StatusSMSRejected, StatusSMSDelivered, StatusBulkSMSCompleted, StatusBulkSMSFailed:
+		return true
+	}
+	return false
+}
+
+// IsSuccess reports whether the status is a successful terminal status
+func (ds DeliveryStatus) IsSuccess() bool {
+	return ds == StatusSMSDelivered || ds == StatusBulkSMSCompleted
+}
+
 // SMSResponse is response returned from Jusibe `send_sms` endpoint
 type SMSResponse struct {
-	Status         string `json:"status"`
-	MessageID      string `json:"message_id"`
-	SMSCreditsUsed int    `json:"sms_credits_used"`
+	Status         DeliveryStatus `json:"status"`
+	MessageID      string         `json:"message_id"`
+	SMSCreditsUsed int            `json:"sms_credits_used"`
 }
 
 // SMSDeliveryResponse is response returned from Jusibe `delivery_status` endpoint
 type SMSDeliveryResponse struct {
-	MessageID     string `json:"message_id"`
-	Status        string `json:"status"`
-	DateSent      string `json:"date_sent"`
-	DateDelivered string `json:"date_delivered"`
+	MessageID     string         `json:"message_id"`
+	Status        DeliveryStatus `json:"status"`
+	DateSent      string         `json:"date_sent"`
+	DateDelivered string         `json:"date_delivered"`
+
+	location *time.Location
+}
+
+// SentAt parses DateSent in the client timezone
+// It returns the zero time.Time when DateSent is empty
+func (sds *SMSDeliveryResponse) SentAt() (time.Time, error) {
+	return ParseTime(sds.DateSent, sds.location)
+}
+
+// DeliveredAt parses DateDelivered in the client timezone
+// It returns the zero time.Time when the SMS is yet to be delivered
+func (sds *SMSDeliveryResponse) DeliveredAt() (time.Time, error) {
+	return ParseTime(sds.DateDelivered, sds.location)
 }
 
 // SMSCreditsResponse is response returned from Jusibe `get_credits` endpoint
@@ -40,23 +144,70 @@ type SMSCreditsResponse struct {
 	SMSCredits string `json:"sms_credits"`
 }
 
+// Credits parses SMSCredits
+func (scr *SMSCreditsResponse) Credits() (float64, error) {
+	return ParseCredits(scr.SMSCredits)
+}
+
 // BulkSMSResponse is response returned form Jusibe `bulk/send_sms` endpoint
 type BulkSMSResponse struct {
-	Status    string `json:"status"`
-	MessageID string `json:"bulk_message_id"`
+	Status    DeliveryStatus `json:"status"`
+	MessageID string         `json:"bulk_message_id"`
 }
 
 // BulkSMSStatusResponse is response from Jusibe `bulk/status` endpoint
 type BulkSMSStatusResponse struct {
 	BulkMessageID string `json:"bulk_message_id"`
 
-	Status    string `json:"status"`
-	Created   string `json:"created"`
-	Processed string `json:"processed"`
+	Status    DeliveryStatus `json:"status"`
+	Created   string         `json:"created"`
+	Processed string         `json:"processed"`
 
 	TotalNumbers string `json:"total_numbers"`
 
 	TotalUniqueNumbers  string `json:"total_unique_numbers"`
 	TotalValidNumbers   string `json:"total_valid_numbers"`
 	TotalInvalidNumbers string `json:"total_invalid_numbers"`
+
+	location *time.Location
+}
+
+// BulkSMSCounts are the parsed number counts of a BulkSMSStatusResponse
+type BulkSMSCounts struct {
+	Total   int
+	Unique  int
+	Valid   int
+	Invalid int
+}
+
+// CreatedAt parses Created in the client timezone
+func (bss *BulkSMSStatusResponse) CreatedAt() (time.Time, error) {
+	return ParseTime(bss.Created, bss.location)
+}
+
+// ProcessedAt parses Processed in the client timezone
+// It returns the zero time.Time when the Bulk SMS is yet to be processed
+func (bss *BulkSMSStatusResponse) ProcessedAt() (time.Time, error) {
+	return ParseTime(bss.Processed, bss.location)
+}
+
+// Counts parses TotalNumbers, TotalUniqueNumbers, TotalValidNumbers and TotalInvalidNumbers
+func (bss *BulkSMSStatusResponse) Counts() (counts BulkSMSCounts, err error) {
+	fields := []struct {
+		raw string
+		n   *int
+	}{
+		{bss.TotalNumbers, &counts.Total},
+		{bss.TotalUniqueNumbers, &counts.Unique},
+		{bss.TotalValidNumbers, &counts.Valid},
+		{bss.TotalInvalidNumbers, &counts.Invalid},
+	}
+
+	for _, f := range fields {
+		if *f.n, err = ParseCount(f.raw); err != nil {
+			return
+		}
+	}
+
+	return
 }
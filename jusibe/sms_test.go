@@ -0,0 +1,134 @@
+package jusibe
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryStatus(t *testing.T) {
+	t.Run("ParseDeliveryStatus should match known statuses case insensitively", func(t *testing.T) {
+		assert.Equal(t, StatusSMSDelivered, ParseDeliveryStatus("delivered"))
+		assert.Equal(t, StatusBulkSMSProcessing, ParseDeliveryStatus(" PROCESSING "))
+		assert.Equal(t, StatusUnknown, ParseDeliveryStatus(""))
+	})
+
+	t.Run("unknown statuses should preserve the raw value", func(t *testing.T) {
+		ds := ParseDeliveryStatus("Queued")
+
+		assert.Equal(t, DeliveryStatus("Queued"), ds)
+		assert.Equal(t, "Queued", ds.String())
+		assert.False(t, ds.IsKnown())
+		assert.False(t, ds.IsTerminal())
+		assert.False(t, StatusUnknown.IsKnown())
+	})
+
+	t.Run("IsTerminal and IsSuccess", func(t *testing.T) {
+		for _, ds := range []DeliveryStatus{StatusSMSRejected, StatusSMSDelivered, StatusBulkSMSCompleted, StatusBulkSMSFailed} {
+			assert.True(t, ds.IsTerminal(), "%s should be terminal", ds)
+		}
+		for _, ds := range []DeliveryStatus{StatusSMSSent, StatusBulkSMSSubmitted, StatusBulkSMSProcessing} {
+			assert.False(t, ds.IsTerminal(), "%s should not be terminal", ds)
+		}
+
+		assert.True(t, StatusSMSDelivered.IsSuccess())
+		assert.True(t, StatusBulkSMSCompleted.IsSuccess())
+		assert.False(t, StatusSMSRejected.IsSuccess())
+		assert.False(t, StatusBulkSMSFailed.IsSuccess())
+	})
+
+	t.Run("responses should decode status into DeliveryStatus", func(t *testing.T) {
+		var sds SMSDeliveryResponse
+		assert.NoError(t, json.Unmarshal([]byte(`{"message_id": "xyz123", "status": "DELIVERED"}`), &sds))
+		assert.Equal(t, StatusSMSDelivered, sds.Status)
+
+		var bss BulkSMSStatusResponse
+		assert.NoError(t, json.Unmarshal([]byte(`{"bulk_message_id": "xeqd6rs3d26", "status": null}`), &bss))
+		assert.Equal(t, StatusUnknown, bss.Status)
+
+		var sr SMSResponse
+		assert.Error(t, json.Unmarshal([]byte(`{"status": 1}`), &sr))
+
+		data, err := json.Marshal(&SMSResponse{Status: StatusSMSSent})
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"status":"Sent"`)
+	})
+}
+
+func TestResponseParsing(t *testing.T) {
+	t.Run("ParseTime should parse in Africa/Lagos by default", func(t *testing.T) {
+		ts, err := ParseTime("2015-05-19 04:34:48", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2015, 5, 19, 3, 34, 48, 0, time.UTC), ts.UTC())
+
+		ts, err = ParseTime("2015-05-19 04:34:48", time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2015, 5, 19, 4, 34, 48, 0, time.UTC), ts)
+	})
+
+	t.Run("FormatTime should format in Africa/Lagos by default", func(t *testing.T) {
+		ts := time.Date(2015, 5, 19, 3, 34, 48, 0, time.UTC)
+		assert.Equal(t, "2015-05-19 04:34:48", FormatTime(ts, nil))
+		assert.Equal(t, "2015-05-19 03:34:48", FormatTime(ts, time.UTC))
+
+		parsed, err := ParseTime(FormatTime(ts.In(time.FixedZone("PST", -8*60*60)), nil), nil)
+		assert.NoError(t, err)
+		assert.True(t, ts.Equal(parsed), "should round-trip from any timezone")
+	})
+
+	t.Run("ParseTime should tolerate empty values", func(t *testing.T) {
+		for _, value := range []string{"", "null", "0000-00-00 00:00:00"} {
+			ts, err := ParseTime(value, nil)
+			assert.NoError(t, err)
+			assert.True(t, ts.IsZero())
+		}
+
+		_, err := ParseTime("19/05/2015", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("ParseCount and ParseCredits", func(t *testing.T) {
+		n, err := ParseCount(" 12 ")
+		assert.NoError(t, err)
+		assert.Equal(t, 12, n)
+
+		n, err = ParseCount("")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+
+		_, err = ParseCount("two")
+		assert.Error(t, err)
+
+		credits, err := ParseCredits("1,200.50")
+		assert.NoError(t, err)
+		assert.Equal(t, 1200.5, credits)
+	})
+
+	t.Run("response accessors should use the client Location", func(t *testing.T) {
+		loc := time.FixedZone("TEST", 2*60*60)
+		sds := &SMSDeliveryResponse{DateSent: "2015-05-19 04:34:48", location: loc}
+
+		sentAt, err := sds.SentAt()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2015, 5, 19, 2, 34, 48, 0, time.UTC), sentAt.UTC())
+
+		deliveredAt, err := sds.DeliveredAt()
+		assert.NoError(t, err)
+		assert.True(t, deliveredAt.IsZero())
+
+		bss := &BulkSMSStatusResponse{Created: "2019-04-02 15:23:13", TotalNumbers: "3", TotalUniqueNumbers: "2", TotalValidNumbers: "2", TotalInvalidNumbers: "0"}
+		createdAt, err := bss.CreatedAt()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2019, 4, 2, 14, 23, 13, 0, time.UTC), createdAt.UTC())
+
+		counts, err := bss.Counts()
+		assert.NoError(t, err)
+		assert.Equal(t, BulkSMSCounts{Total: 3, Unique: 2, Valid: 2, Invalid: 0}, counts)
+
+		credits, err := (&SMSCreditsResponse{SMSCredits: "100"}).Credits()
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, credits)
+	})
+}
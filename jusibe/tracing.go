@@ -0,0 +1,60 @@
+package jusibe
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer starts a span per API call and propagates it on outbound requests
+// It mirrors the subset of OpenTelemetry used by the client, so adapting an OpenTelemetry tracer and propagator takes a few lines
+// See the tracing package for a W3C Trace Context implementation
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any, and returns a context holding the new span
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+
+	// Inject writes the trace context of the span in ctx into header, e.g a traceparent header
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a single traced operation
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// tracingMiddleware wraps every call in a span started with t
+func tracingMiddleware(t Tracer) Middleware {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call *Call) error {
+			ctx, span := t.Start(ctx, "jusibe."+string(call.Operation))
+			defer span.End()
+
+			span.SetAttribute("jusibe.operation", string(call.Operation))
+			span.SetAttribute("http.method", call.Request.Method)
+			span.SetAttribute("http.path", call.Request.URL.Path)
+			if reference := callReference(call); reference != "" {
+				span.SetAttribute("jusibe.reference", reference)
+			}
+
+			t.Inject(ctx, call.Request.Header)
+
+			err := next(ctx, call)
+
+			if call.Response != nil {
+				span.SetAttribute("http.status_code", call.Response.StatusCode)
+			}
+			if messageID := callMessageID(call); messageID != "" {
+				span.SetAttribute("jusibe.message_id", messageID)
+			}
+			if ssr, ok := call.Result.(*SMSResponse); ok && err == nil {
+				span.SetAttribute("jusibe.sms_credits_used", ssr.SMSCreditsUsed)
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
+		}
+	}
+}
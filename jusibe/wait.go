@@ -0,0 +1,129 @@
+package jusibe
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultWaitInitialInterval = (time.Second * 2)
+	defaultWaitMaxInterval     = (time.Second * 30)
+	defaultWaitMultiplier      = 1.5
+)
+
+// WaitOptions configures how WaitForDelivery and WaitForBulkCompletion poll Jusibe
+// A nil *WaitOptions uses the default values
+type WaitOptions struct {
+	// InitialInterval is the wait between the first and second status checks. Defaults to 2s
+	InitialInterval time.Duration
+
+	// MaxInterval caps the wait between status checks. Defaults to 30s
+	MaxInterval time.Duration
+
+	// Multiplier grows the wait after every status check. Defaults to 1.5
+	Multiplier float64
+
+	// OnStatus is called whenever a new status is observed
+	OnStatus func(StatusObservation)
+}
+
+func (o *WaitOptions) withDefaults() *WaitOptions {
+	cp := WaitOptions{}
+	if o != nil {
+		cp = *o
+	}
+	if cp.InitialInterval <= 0 {
+		cp.InitialInterval = defaultWaitInitialInterval
+	}
+	if cp.MaxInterval <= 0 {
+		cp.MaxInterval = defaultWaitMaxInterval
+	}
+	if cp.Multiplier < 1 {
+		cp.Multiplier = defaultWaitMultiplier
+	}
+	return &cp
+}
+
+// StatusObservation is a status seen while polling, along with when it was first seen
+type StatusObservation struct {
+	Status     DeliveryStatus
+	ObservedAt time.Time
+}
+
+// WaitForDelivery polls CheckSMSDeliveryStatus with backoff until the SMS reaches a terminal status (Delivered or Rejected)
+// It returns the last *SMSDeliveryResponse and every status change observed, in order
+// Polling stops early with an error when a status check fails or ctx is done
+func WaitForDelivery(ctx context.Context, c Client, messageID string, opts *WaitOptions) (sds *SMSDeliveryResponse, history []StatusObservation, err error) {
+	history, err = poll(ctx, opts, func(ctx context.Context) (status DeliveryStatus, err error) {
+		res, _, err := c.CheckSMSDeliveryStatus(ctx, messageID)
+		if err == nil {
+			sds, status = res, res.Status
+		}
+		return
+	})
+
+	return
+}
+
+// WaitForBulkCompletion polls CheckBulkSMSStatus with backoff until the Bulk SMS reaches a terminal status (Completed or Failed)
+// It returns the last *BulkSMSStatusResponse and every status change observed, in order
+// Polling stops early with an error when a status check fails or ctx is done
+func WaitForBulkCompletion(ctx context.Context, c Client, bulkMessageID string, opts *WaitOptions) (bss *BulkSMSStatusResponse, history []StatusObservation, err error) {
+	history, err = poll(ctx, opts, func(ctx context.Context) (status DeliveryStatus, err error) {
+		res, _, err := c.CheckBulkSMSStatus(ctx, bulkMessageID)
+		if err == nil {
+			bss, status = res, res.Status
+		}
+		return
+	})
+
+	return
+}
+
+// WaitForDelivery calls the package level WaitForDelivery function with the Jusibe client
+func (j *Jusibe) WaitForDelivery(ctx context.Context, messageID string, opts *WaitOptions) (*SMSDeliveryResponse, []StatusObservation, error) {
+	return WaitForDelivery(ctx, j, messageID, opts)
+}
+
+// WaitForBulkCompletion calls the package level WaitForBulkCompletion function with the Jusibe client
+func (j *Jusibe) WaitForBulkCompletion(ctx context.Context, bulkMessageID string, opts *WaitOptions) (*BulkSMSStatusResponse, []StatusObservation, error) {
+	return WaitForBulkCompletion(ctx, j, bulkMessageID, opts)
+}
+
+// poll calls check until it returns a terminal status, recording every status change
+func poll(ctx context.Context, opts *WaitOptions, check func(ctx context.Context) (DeliveryStatus, error)) (history []StatusObservation, err error) {
+	opts = opts.withDefaults()
+	interval := opts.InitialInterval
+
+	for {
+		var status DeliveryStatus
+		if status, err = check(ctx); err != nil {
+			return
+		}
+
+		if len(history) == 0 || history[len(history)-1].Status != status {
+			observation := StatusObservation{Status: status, ObservedAt: time.Now()}
+			history = append(history, observation)
+			if opts.OnStatus != nil {
+				opts.OnStatus(observation)
+			}
+		}
+
+		if status.IsTerminal() {
+			return
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+			return
+		case <-timer.C:
+		}
+
+		if interval = time.Duration(float64(interval) * opts.Multiplier); interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package jusibe_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibefake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWait(t *testing.T) {
+	fastOptions := &jusibe.WaitOptions{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+	t.Run("WaitForDelivery should poll until a terminal status", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		fake.DeliveryScript = []jusibe.DeliveryStatus{jusibe.StatusSMSSent, jusibe.StatusSMSSent, jusibe.StatusSMSDelivered}
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		var observed []jusibe.DeliveryStatus
+		opts := *fastOptions
+		opts.OnStatus = func(o jusibe.StatusObservation) { observed = append(observed, o.Status) }
+
+		sds, history, err := jusibe.WaitForDelivery(context.Background(), fake, s.MessageID, &opts)
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusSMSDelivered, sds.Status)
+		assert.Len(t, history, 2, "should only record status changes")
+		assert.Equal(t, jusibe.StatusSMSSent, history[0].Status)
+		assert.Equal(t, jusibe.StatusSMSDelivered, history[1].Status)
+		assert.False(t, history[1].ObservedAt.Before(history[0].ObservedAt))
+		assert.Equal(t, []jusibe.DeliveryStatus{jusibe.StatusSMSSent, jusibe.StatusSMSDelivered}, observed)
+	})
+
+	t.Run("WaitForDelivery should stop when context is done", func(t *testing.T) {
+		fake := jusibefake.New(10)
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		sds, history, err := jusibe.WaitForDelivery(ctx, fake, s.MessageID, fastOptions)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, jusibe.StatusSMSSent, sds.Status)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("WaitForDelivery should stop when a status check fails", func(t *testing.T) {
+		fake := jusibefake.New(10)
+
+		_, _, err := jusibe.WaitForDelivery(context.Background(), fake, "unknown", fastOptions)
+		assert.True(t, errors.Is(err, jusibe.ErrNotFound))
+	})
+
+	t.Run("WaitForBulkCompletion should poll until a terminal status", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		fake.BulkScript = []jusibe.DeliveryStatus{jusibe.StatusBulkSMSProcessing, jusibe.StatusBulkSMSFailed}
+
+		bs, _, err := fake.SendBulkSMS(context.Background(), "09001000101,08030000000", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		bss, history, err := jusibe.WaitForBulkCompletion(context.Background(), fake, bs.MessageID, fastOptions)
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusBulkSMSFailed, bss.Status)
+		assert.Len(t, history, 2)
+	})
+}
@@ -0,0 +1,389 @@
+package jusibe
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatcherWorkers      = 4
+	defaultWatcherPollInterval = (time.Second * 10)
+	defaultWatcherMaxAge       = (time.Hour * 24)
+	defaultWatcherEventBuffer  = 64
+)
+
+// ErrWatcherClosed is returned when watching a message with a closed Watcher
+var ErrWatcherClosed = errors.New("jusibe: watcher closed")
+
+// WatcherConfig is Watcher configuration
+// All fields are optional
+type WatcherConfig struct {
+	// Workers is the number of concurrent status checks. Defaults to 4
+	Workers int
+
+	// PollInterval is the wait between two status checks of the same message. Defaults to 10s
+	PollInterval time.Duration
+
+	// RateLimit limits the rate of status checks across every worker. Status checks are not limited when nil
+	RateLimit *RateLimit
+
+	// MaxAge is how long a message is watched before it is evicted without reaching a terminal status. Defaults to 24h
+	MaxAge time.Duration
+
+	// OnEvent receives events instead of the Events channel when set
+	// It is called from the worker goroutines, so it must be safe for concurrent use
+	OnEvent func(DeliveryEvent)
+
+	// EventBuffer is the capacity of the Events channel. Defaults to 64
+	// Workers block when the channel is full, which slows down status checks until events are consumed
+	EventBuffer int
+}
+
+// DeliveryEvent is emitted by a Watcher when the status of a watched message changes, a status check fails,
+// or the message stops being watched
+type DeliveryEvent struct {
+	MessageID string
+
+	// Previous is the status before this event. It is StatusUnknown on the first status check
+	Previous DeliveryStatus
+
+	// Status is the current status
+	Status DeliveryStatus
+
+	// Response is the response of the status check which produced the event. It is nil when Err is set
+	Response *SMSDeliveryResponse
+
+	// Err is the error returned by the status check, if any
+	Err error
+
+	// Done reports whether the message was evicted, because Status is terminal, because MaxAge elapsed,
+	// or because Err is permanent, e.g ErrNotFound or ErrUnauthorized. Transient errors keep the message watched
+	Done bool
+
+	ObservedAt time.Time
+}
+
+type watchedMessage struct {
+	id      string
+	status  DeliveryStatus
+	added   time.Time
+	next    time.Time
+	index   int
+	removed bool
+}
+
+// watchQueue is a min-heap of watched messages ordered by their next status check
+type watchQueue []*watchedMessage
+
+func (q watchQueue) Len() int           { return len(q) }
+func (q watchQueue) Less(i, k int) bool { return q[i].next.Before(q[k].next) }
+func (q watchQueue) Swap(i, k int) {
+	q[i], q[k] = q[k], q[i]
+	q[i].index = i
+	q[k].index = k
+}
+
+func (q *watchQueue) Push(x interface{}) {
+	m := x.(*watchedMessage)
+	m.index = len(*q)
+	*q = append(*q, m)
+}
+
+func (q *watchQueue) Pop() interface{} {
+	old := *q
+	m := old[len(old)-1]
+	old[len(old)-1] = nil
+	m.index = -1
+	*q = old[:len(old)-1]
+	return m
+}
+
+// Watcher tracks the delivery status of many messages with a bounded pool of workers
+// Create a Watcher with NewWatcher
+type Watcher struct {
+	client Client
+	cfg    WatcherConfig
+
+	limiter *rateLimiter
+	events  chan DeliveryEvent
+	jobs    chan *watchedMessage
+	wake    chan struct{}
+
+	mu       sync.Mutex
+	messages map[string]*watchedMessage
+	queue    watchQueue
+	closed   bool
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewWatcher creates a Watcher checking delivery statuses with c and starts its workers
+// The caller should call Close when finished, to stop the workers
+func NewWatcher(c Client, cfg *WatcherConfig) (w *Watcher, err error) {
+	var wc WatcherConfig
+	if cfg != nil {
+		wc = *cfg
+	}
+	if wc.Workers <= 0 {
+		wc.Workers = defaultWatcherWorkers
+	}
+	if wc.PollInterval <= 0 {
+		wc.PollInterval = defaultWatcherPollInterval
+	}
+	if wc.MaxAge <= 0 {
+		wc.MaxAge = defaultWatcherMaxAge
+	}
+	if wc.EventBuffer <= 0 {
+		wc.EventBuffer = defaultWatcherEventBuffer
+	}
+
+	w = &Watcher{
+		client:   c,
+		cfg:      wc,
+		jobs:     make(chan *watchedMessage),
+		wake:     make(chan struct{}, 1),
+		messages: map[string]*watchedMessage{},
+	}
+
+	if wc.RateLimit != nil {
+		if err = wc.RateLimit.validate(); err != nil {
+			return nil, err
+		}
+		w.limiter = newRateLimiter(wc.RateLimit)
+	}
+
+	if wc.OnEvent == nil {
+		w.events = make(chan DeliveryEvent, wc.EventBuffer)
+	}
+
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	w.wg.Add(1 + wc.Workers)
+	go w.schedule()
+	for i := 0; i < wc.Workers; i++ {
+		go w.work()
+	}
+
+	return
+}
+
+// Events returns the channel events are emitted on
+// It is closed by Close, and is nil when WatcherConfig.OnEvent is set
+func (w *Watcher) Events() <-chan DeliveryEvent {
+	return w.events
+}
+
+// Watch starts tracking messageID. Watching a message which is already tracked is a no-op
+func (w *Watcher) Watch(messageID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrWatcherClosed
+	}
+
+	if _, ok := w.messages[messageID]; ok {
+		return nil
+	}
+
+	now := time.Now()
+	m := &watchedMessage{id: messageID, added: now, next: now}
+	w.messages[messageID] = m
+	heap.Push(&w.queue, m)
+	w.notify()
+
+	return nil
+}
+
+// Unwatch stops tracking messageID without emitting an event
+func (w *Watcher) Unwatch(messageID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.remove(messageID)
+}
+
+// Len returns the number of messages being tracked
+func (w *Watcher) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.messages)
+}
+
+// Close stops the workers, cancelling in-flight status checks, and closes the Events channel
+// Messages which are still tracked are dropped without an event
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		w.cancel()
+		w.wg.Wait()
+
+		if w.events != nil {
+			close(w.events)
+		}
+	})
+
+	return nil
+}
+
+// remove forgets messageID
+// It must be called with w.mu held
+func (w *Watcher) remove(messageID string) {
+	m, ok := w.messages[messageID]
+	if !ok {
+		return
+	}
+
+	delete(w.messages, messageID)
+	m.removed = true
+	if m.index >= 0 {
+		heap.Remove(&w.queue, m.index)
+	}
+}
+
+// notify wakes the scheduler up without blocking
+func (w *Watcher) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// schedule hands due messages over to the workers
+func (w *Watcher) schedule() {
+	defer w.wg.Done()
+	defer close(w.jobs)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		w.mu.Lock()
+		var due *watchedMessage
+		wait := time.Hour
+		if len(w.queue) > 0 {
+			if wait = time.Until(w.queue[0].next); wait <= 0 {
+				due = heap.Pop(&w.queue).(*watchedMessage)
+			}
+		}
+		w.mu.Unlock()
+
+		if due != nil {
+			select {
+			case w.jobs <- due:
+			case <-w.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// work checks the status of messages handed over by the scheduler
+func (w *Watcher) work() {
+	defer w.wg.Done()
+
+	for m := range w.jobs {
+		if err := w.limiter.wait(w.ctx); err != nil {
+			return
+		}
+
+		sds, _, err := w.client.CheckSMSDeliveryStatus(w.ctx, m.id)
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		if event, ok := w.update(m, sds, err); ok {
+			w.emit(event)
+		}
+	}
+}
+
+// update records the outcome of a status check, rescheduling or evicting the message
+// It reports whether an event should be emitted
+func (w *Watcher) update(m *watchedMessage, sds *SMSDeliveryResponse, err error) (event DeliveryEvent, ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if m.removed {
+		return
+	}
+
+	now := time.Now()
+	event = DeliveryEvent{
+		MessageID:  m.id,
+		Previous:   m.status,
+		Status:     m.status,
+		Err:        err,
+		ObservedAt: now,
+	}
+
+	if err == nil {
+		event.Response = sds
+		event.Status = sds.Status
+		m.status = sds.Status
+	}
+
+	if m.status.IsTerminal() || now.Sub(m.added) >= w.cfg.MaxAge || isPermanentStatusError(err) {
+		event.Done = true
+		delete(w.messages, m.id)
+		m.removed = true
+	} else {
+		m.next = now.Add(w.cfg.PollInterval)
+		heap.Push(&w.queue, m)
+		w.notify()
+	}
+
+	ok = event.Err != nil || event.Done || event.Status != event.Previous
+
+	return
+}
+
+// isPermanentStatusError reports whether checking the status again would fail the same way
+// Rate limiting, 5xx responses and network errors are transient, while other http response codes are permanent
+func isPermanentStatusError(err error) bool {
+	if err == nil || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer) {
+		return false
+	}
+
+	var apiErr *APIError
+	return errors.As(err, &apiErr) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnauthorized)
+}
+
+// emit delivers an event to OnEvent or the Events channel
+func (w *Watcher) emit(event DeliveryEvent) {
+	if w.cfg.OnEvent != nil {
+		w.cfg.OnEvent(event)
+		return
+	}
+
+	select {
+	case w.events <- event:
+	case <-w.ctx.Done():
+	}
+}
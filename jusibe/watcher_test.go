@@ -0,0 +1,174 @@
+package jusibe_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibefake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Run("Watcher should emit status changes and evict terminal messages", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		fake.DeliveryScript = []jusibe.DeliveryStatus{jusibe.StatusSMSSent, jusibe.StatusSMSSent, jusibe.StatusSMSDelivered}
+
+		w, err := jusibe.NewWatcher(fake, &jusibe.WatcherConfig{Workers: 2, PollInterval: time.Millisecond})
+		assert.NoError(t, err)
+		defer w.Close()
+
+		ids := map[string]bool{}
+		for i := 0; i < 3; i++ {
+			s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+			assert.NoError(t, err)
+			ids[s.MessageID] = true
+
+			assert.NoError(t, w.Watch(s.MessageID))
+			assert.NoError(t, w.Watch(s.MessageID), "duplicate ids should be ignored")
+		}
+		assert.Equal(t, 3, w.Len())
+
+		events := map[string][]jusibe.DeliveryEvent{}
+		timeout := time.After(5 * time.Second)
+		for done := 0; done < 3; {
+			select {
+			case event := <-w.Events():
+				assert.NoError(t, event.Err)
+				assert.True(t, ids[event.MessageID])
+				events[event.MessageID] = append(events[event.MessageID], event)
+				if event.Done {
+					done++
+				}
+			case <-timeout:
+				t.Fatal("timed out waiting for events")
+			}
+		}
+
+		for id, messageEvents := range events {
+			assert.Len(t, messageEvents, 2, "should only emit status changes for %s", id)
+			assert.Equal(t, jusibe.StatusUnknown, messageEvents[0].Previous)
+			assert.Equal(t, jusibe.StatusSMSSent, messageEvents[0].Status)
+			assert.Equal(t, jusibe.StatusSMSSent, messageEvents[1].Previous)
+			assert.Equal(t, jusibe.StatusSMSDelivered, messageEvents[1].Status)
+			assert.True(t, messageEvents[1].Done)
+		}
+
+		assert.Equal(t, 0, w.Len())
+	})
+
+	t.Run("Watcher should report errors and keep watching", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		fake.DeliveryScript = []jusibe.DeliveryStatus{jusibe.StatusSMSRejected}
+		fake.Fail(jusibefake.OpCheckSMSDeliveryStatus, errors.New("boom"))
+
+		var mu sync.Mutex
+		var events []jusibe.DeliveryEvent
+		done := make(chan struct{})
+		w, err := jusibe.NewWatcher(fake, &jusibe.WatcherConfig{PollInterval: time.Millisecond, OnEvent: func(event jusibe.DeliveryEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+			if event.Done {
+				close(done)
+			}
+		}})
+		assert.NoError(t, err)
+		assert.Nil(t, w.Events())
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.NoError(t, w.Watch(s.MessageID))
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+		assert.NoError(t, w.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, events, 2)
+		assert.EqualError(t, events[0].Err, "boom")
+		assert.False(t, events[0].Done)
+		assert.Equal(t, jusibe.StatusSMSRejected, events[1].Status)
+	})
+
+	t.Run("Watcher should stop watching messages on permanent errors", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		fake.Fail(jusibefake.OpCheckSMSDeliveryStatus, &jusibe.APIError{StatusCode: 503})
+
+		w, err := jusibe.NewWatcher(fake, &jusibe.WatcherConfig{PollInterval: time.Millisecond})
+		assert.NoError(t, err)
+		defer w.Close()
+
+		assert.NoError(t, w.Watch("unknown"))
+
+		timeout := time.After(5 * time.Second)
+		var events []jusibe.DeliveryEvent
+		for len(events) < 2 {
+			select {
+			case event := <-w.Events():
+				events = append(events, event)
+			case <-timeout:
+				t.Fatal("timed out waiting for events")
+			}
+		}
+
+		assert.True(t, errors.Is(events[0].Err, jusibe.ErrServer))
+		assert.False(t, events[0].Done, "transient errors should keep the message watched")
+		assert.True(t, errors.Is(events[1].Err, jusibe.ErrNotFound))
+		assert.True(t, events[1].Done)
+		assert.Equal(t, 0, w.Len())
+	})
+
+	t.Run("Watcher should evict messages after MaxAge", func(t *testing.T) {
+		fake := jusibefake.New(10)
+
+		w, err := jusibe.NewWatcher(fake, &jusibe.WatcherConfig{PollInterval: time.Millisecond, MaxAge: 5 * time.Millisecond})
+		assert.NoError(t, err)
+		defer w.Close()
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.NoError(t, w.Watch(s.MessageID))
+
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-w.Events():
+				if !event.Done {
+					continue
+				}
+				assert.Equal(t, jusibe.StatusSMSSent, event.Status)
+				assert.Equal(t, 0, w.Len())
+				return
+			case <-timeout:
+				t.Fatal("timed out waiting for eviction")
+			}
+		}
+	})
+
+	t.Run("Close should stop the workers and close Events", func(t *testing.T) {
+		fake := jusibefake.New(10)
+
+		w, err := jusibe.NewWatcher(fake, &jusibe.WatcherConfig{PollInterval: time.Hour})
+		assert.NoError(t, err)
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.NoError(t, w.Watch(s.MessageID))
+
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Close(), "Close should be idempotent")
+
+		for range w.Events() {
+		}
+
+		assert.Equal(t, jusibe.ErrWatcherClosed, w.Watch("another"))
+	})
+}
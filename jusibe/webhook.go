@@ -0,0 +1,352 @@
+package jusibe
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebhookSecretHeader    = "X-Jusibe-Secret"
+	defaultWebhookSignatureHeader = "X-Jusibe-Signature"
+	defaultWebhookDedupeWindow    = (time.Hour * 24)
+	defaultWebhookMaxBodyBytes    = (1 << 16)
+)
+
+// WebhookConfig is WebhookHandler configuration
+// At least one of Secret, HMACKey or AllowedIPs is required. Every configured check must pass
+type WebhookConfig struct {
+	// Secret is a shared secret the callbacks must send in the SecretHeader header
+	Secret string
+
+	// SecretHeader is the header holding Secret. Defaults to X-Jusibe-Secret
+	SecretHeader string
+
+	// HMACKey is the key of the hex encoded HMAC-SHA256 signature of the request body the callbacks must send
+	// in the SignatureHeader header, optionally prefixed with "sha256="
+	HMACKey []byte
+
+	// SignatureHeader is the header holding the HMAC signature. Defaults to X-Jusibe-Signature
+	SignatureHeader string
+
+	// AllowedIPs are the IP addresses or CIDR ranges callbacks may come from, e.g 203.0.113.7 or 203.0.113.0/24
+	AllowedIPs []string
+
+	// TrustForwardedFor makes the IP allowlist check the first X-Forwarded-For address instead of the remote address
+	// Only set it when the handler is behind a proxy which overwrites X-Forwarded-For
+	TrustForwardedFor bool
+
+	// DedupeWindow is how long a delivery report is remembered to drop retried callbacks. Defaults to 24h
+	DedupeWindow time.Duration
+
+	// MaxBodyBytes limits the size of callback bodies. Defaults to 64KiB
+	MaxBodyBytes int64
+
+	// Location is the timezone the report timestamps are parsed in. It defaults to Africa/Lagos when nil
+	Location *time.Location
+}
+
+// WebhookHandler is an http.Handler receiving delivery report callbacks from Jusibe
+// Reports are sent as a JSON or form-encoded POST body with the fields of SMSDeliveryResponse, i.e message_id,
+// status, date_sent and date_delivered
+// Create a WebhookHandler with NewWebhookHandler
+type WebhookHandler struct {
+	cfg    WebhookConfig
+	handle func(ctx context.Context, event DeliveryEvent) error
+	nets   []*net.IPNet
+
+	mu        sync.Mutex
+	seen      map[string]webhookClaim
+	lastPrune time.Time
+}
+
+// webhookClaim is a delivery report seen by a WebhookHandler
+type webhookClaim struct {
+	at time.Time
+
+	// inFlight reports whether the handle function is still processing the report
+	inFlight bool
+}
+
+// NewWebhookHandler creates a WebhookHandler dispatching every delivery report to handle
+// A report is delivered once per message id and status. When handle returns an error, the callback is answered with
+// a 500 http response code so that it is retried. Duplicates arriving while handle is still running are answered with
+// a 409 http response code, so that they are retried rather than lost if handle fails
+func NewWebhookHandler(cfg *WebhookConfig, handle func(ctx context.Context, event DeliveryEvent) error) (h *WebhookHandler, err error) {
+	if handle == nil {
+		return nil, errors.New("jusibe: a webhook handle function is required")
+	}
+
+	var wc WebhookConfig
+	if cfg != nil {
+		wc = *cfg
+	}
+
+	if wc.Secret == "" && len(wc.HMACKey) == 0 && len(wc.AllowedIPs) == 0 {
+		return nil, errors.New("jusibe: at least one of Secret, HMACKey or AllowedIPs is required to authenticate webhooks")
+	}
+
+	if wc.SecretHeader == "" {
+		wc.SecretHeader = defaultWebhookSecretHeader
+	}
+	if wc.SignatureHeader == "" {
+		wc.SignatureHeader = defaultWebhookSignatureHeader
+	}
+	if wc.DedupeWindow <= 0 {
+		wc.DedupeWindow = defaultWebhookDedupeWindow
+	}
+	if wc.MaxBodyBytes <= 0 {
+		wc.MaxBodyBytes = defaultWebhookMaxBodyBytes
+	}
+
+	h = &WebhookHandler{cfg: wc, handle: handle, seen: map[string]webhookClaim{}}
+
+	for _, allowed := range wc.AllowedIPs {
+		if !strings.Contains(allowed, "/") {
+			if ip := net.ParseIP(allowed); ip != nil && ip.To4() != nil {
+				allowed += "/32"
+			} else {
+				allowed += "/128"
+			}
+		}
+
+		_, ipNet, parseErr := net.ParseCIDR(allowed)
+		if parseErr != nil {
+			return nil, errors.New("jusibe: invalid AllowedIPs entry " + allowed)
+		}
+		h.nets = append(h.nets, ipNet)
+	}
+
+	return
+}
+
+// ServeHTTP implements http.Handler
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.ipIsAllowed(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, h.cfg.MaxBodyBytes+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > h.cfg.MaxBodyBytes {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !h.secretIsValid(r) || !h.signatureIsValid(r, body) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	report, err := h.parse(r, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key := report.MessageID + "\x00" + string(report.Status)
+	switch h.claim(key) {
+	case claimDuplicate:
+		w.WriteHeader(http.StatusOK)
+		return
+	case claimInFlight:
+		http.Error(w, "delivery report is being processed", http.StatusConflict)
+		return
+	}
+
+	event := DeliveryEvent{
+		MessageID:  report.MessageID,
+		Status:     report.Status,
+		Response:   report,
+		Done:       report.Status.IsTerminal(),
+		ObservedAt: time.Now(),
+	}
+
+	// The claim is released unless the report was handled, including when handle panics
+	handled := false
+	defer func() {
+		if !handled {
+			h.release(key)
+		}
+	}()
+
+	if err = h.handle(r.Context(), event); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.complete(key)
+	handled = true
+	w.WriteHeader(http.StatusOK)
+}
+
+// parse reads a delivery report from a JSON or form-encoded body
+func (h *WebhookHandler) parse(r *http.Request, body []byte) (report *SMSDeliveryResponse, err error) {
+	report = &SMSDeliveryResponse{location: h.cfg.Location}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" || (mediaType == "" && bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))) {
+		if err = json.Unmarshal(body, report); err != nil {
+			return nil, errors.New("invalid delivery report: " + err.Error())
+		}
+	} else {
+		values, parseErr := url.ParseQuery(string(body))
+		if parseErr != nil {
+			return nil, errors.New("invalid delivery report: " + parseErr.Error())
+		}
+		report.MessageID = values.Get("message_id")
+		report.Status = ParseDeliveryStatus(values.Get("status"))
+		report.DateSent = values.Get("date_sent")
+		report.DateDelivered = values.Get("date_delivered")
+	}
+
+	if report.MessageID == "" {
+		return nil, errors.New("invalid delivery report: message_id is required")
+	}
+
+	return
+}
+
+// ipIsAllowed checks the client address against AllowedIPs
+func (h *WebhookHandler) ipIsAllowed(r *http.Request) bool {
+	if len(h.nets) == 0 {
+		return true
+	}
+
+	addr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if h.cfg.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			addr = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range h.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secretIsValid checks the shared secret header
+func (h *WebhookHandler) secretIsValid(r *http.Request) bool {
+	if h.cfg.Secret == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get(h.cfg.SecretHeader)), []byte(h.cfg.Secret)) == 1
+}
+
+// signatureIsValid checks the HMAC-SHA256 signature header against body
+func (h *WebhookHandler) signatureIsValid(r *http.Request, body []byte) bool {
+	if len(h.cfg.HMACKey) == 0 {
+		return true
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(h.cfg.SignatureHeader), "sha256="))
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(signature, SignWebhook(h.cfg.HMACKey, body))
+}
+
+// claimResult is the outcome of WebhookHandler.claim
+type claimResult int
+
+const (
+	// claimNew means the report must be dispatched
+	claimNew claimResult = iota
+
+	// claimDuplicate means the report was already handled within DedupeWindow
+	claimDuplicate
+
+	// claimInFlight means the report is still being handled by another request
+	claimInFlight
+)
+
+// claim records key as in flight, unless it is in flight or was handled within DedupeWindow
+func (h *WebhookHandler) claim(key string) claimResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+
+	// Expired keys are pruned at most once per minute, so that claims stay cheap
+	if now.Sub(h.lastPrune) >= time.Minute {
+		for k, c := range h.seen {
+			if !c.inFlight && now.Sub(c.at) >= h.cfg.DedupeWindow {
+				delete(h.seen, k)
+			}
+		}
+		h.lastPrune = now
+	}
+
+	if c, ok := h.seen[key]; ok {
+		if c.inFlight {
+			return claimInFlight
+		}
+		if now.Sub(c.at) < h.cfg.DedupeWindow {
+			return claimDuplicate
+		}
+	}
+
+	h.seen[key] = webhookClaim{at: now, inFlight: true}
+	return claimNew
+}
+
+// complete records that key was handled, so that retried callbacks are dropped for DedupeWindow
+func (h *WebhookHandler) complete(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seen[key] = webhookClaim{at: time.Now()}
+}
+
+// release forgets key so that a retried callback is dispatched again
+func (h *WebhookHandler) release(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.seen, key)
+}
+
+// SignWebhook returns the HMAC-SHA256 signature of body with key, as checked by WebhookHandler
+// It is mostly useful to test webhook receivers
+func SignWebhook(key, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
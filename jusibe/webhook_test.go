@@ -0,0 +1,260 @@
+package jusibe
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingReader fails every read, like a body whose client disconnected
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("unexpected EOF")
+}
+
+func TestWebhookHandler(t *testing.T) {
+	report := `{"message_id": "xyz123", "status": "Delivered", "date_sent": "2020-01-02 15:04:05", "date_delivered": "2020-01-02 15:04:09"}`
+
+	// recorder collects the events dispatched by a WebhookHandler
+	type recorder struct {
+		mu     sync.Mutex
+		events []DeliveryEvent
+		err    error
+	}
+	newHandler := func(t *testing.T, cfg *WebhookConfig) (*WebhookHandler, *recorder) {
+		rec := &recorder{}
+		h, err := NewWebhookHandler(cfg, func(ctx context.Context, event DeliveryEvent) error {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			if rec.err != nil {
+				return rec.err
+			}
+			rec.events = append(rec.events, event)
+			return nil
+		})
+		assert.NoError(t, err)
+		return h, rec
+	}
+
+	t.Run("NewWebhookHandler should require authentication", func(t *testing.T) {
+		handle := func(ctx context.Context, event DeliveryEvent) error { return nil }
+
+		_, err := NewWebhookHandler(&WebhookConfig{}, handle)
+		assert.Error(t, err)
+
+		_, err = NewWebhookHandler(&WebhookConfig{AllowedIPs: []string{"not-an-ip"}}, handle)
+		assert.Error(t, err)
+
+		_, err = NewWebhookHandler(&WebhookConfig{Secret: "s3cret"}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("ServeHTTP should dispatch JSON reports and drop retries", func(t *testing.T) {
+		h, rec := newHandler(t, &WebhookConfig{Secret: "s3cret"})
+
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/dlr", strings.NewReader(report))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Jusibe-Secret", "s3cret")
+			res := httptest.NewRecorder()
+
+			h.ServeHTTP(res, req)
+			assert.Equal(t, http.StatusOK, res.Code)
+		}
+
+		if assert.Len(t, rec.events, 1) {
+			event := rec.events[0]
+			assert.Equal(t, "xyz123", event.MessageID)
+			assert.Equal(t, StatusSMSDelivered, event.Status)
+			assert.True(t, event.Done)
+
+			deliveredAt, err := event.Response.DeliveredAt()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Date(2020, 1, 2, 15, 4, 9, 0, defaultLocation), deliveredAt)
+		}
+	})
+
+	t.Run("ServeHTTP should dispatch form-encoded reports", func(t *testing.T) {
+		h, rec := newHandler(t, &WebhookConfig{Secret: "s3cret"})
+
+		body := url.Values{"message_id": {"xyz123"}, "status": {"sent"}}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/dlr", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Jusibe-Secret", "s3cret")
+		res := httptest.NewRecorder()
+
+		h.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		if assert.Len(t, rec.events, 1) {
+			assert.Equal(t, StatusSMSSent, rec.events[0].Status)
+			assert.False(t, rec.events[0].Done)
+		}
+	})
+
+	t.Run("ServeHTTP should authenticate callbacks", func(t *testing.T) {
+		key := []byte("hmac-key")
+		h, rec := newHandler(t, &WebhookConfig{Secret: "s3cret", HMACKey: key, AllowedIPs: []string{"192.0.2.0/24", "2001:db8::1"}})
+
+		send := func(remoteAddr, secret, signature string) int {
+			req := httptest.NewRequest(http.MethodPost, "/dlr", strings.NewReader(report))
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("X-Jusibe-Secret", secret)
+			req.Header.Set("X-Jusibe-Signature", signature)
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+			return res.Code
+		}
+
+		signature := "sha256=" + hex.EncodeToString(SignWebhook(key, []byte(report)))
+
+		assert.Equal(t, http.StatusForbidden, send("198.51.100.1:1234", "s3cret", signature))
+		assert.Equal(t, http.StatusUnauthorized, send("192.0.2.1:1234", "wrong", signature))
+		assert.Equal(t, http.StatusUnauthorized, send("192.0.2.1:1234", "s3cret", "sha256=00"))
+		assert.Empty(t, rec.events)
+
+		assert.Equal(t, http.StatusOK, send("[2001:db8::1]:1234", "s3cret", signature))
+		assert.Len(t, rec.events, 1)
+	})
+
+	t.Run("ServeHTTP should only trust X-Forwarded-For when configured", func(t *testing.T) {
+		for _, trust := range []bool{false, true} {
+			h, _ := newHandler(t, &WebhookConfig{AllowedIPs: []string{"192.0.2.1"}, TrustForwardedFor: trust})
+
+			req := httptest.NewRequest(http.MethodPost, "/dlr", strings.NewReader(report))
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", "192.0.2.1, 10.0.0.1")
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+
+			if trust {
+				assert.Equal(t, http.StatusOK, res.Code)
+			} else {
+				assert.Equal(t, http.StatusForbidden, res.Code)
+			}
+		}
+	})
+
+	t.Run("ServeHTTP should reject invalid requests", func(t *testing.T) {
+		h, _ := newHandler(t, &WebhookConfig{Secret: "s3cret", MaxBodyBytes: 256})
+
+		send := func(method, body string) int {
+			req := httptest.NewRequest(method, "/dlr", strings.NewReader(body))
+			req.Header.Set("X-Jusibe-Secret", "s3cret")
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+			return res.Code
+		}
+
+		assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodGet, ""))
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, `{"status": "Delivered"}`))
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, `{"message_id": `))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, send(http.MethodPost, strings.Repeat("a", 512)))
+
+		req := httptest.NewRequest(http.MethodPost, "/dlr", failingReader{})
+		req.Header.Set("X-Jusibe-Secret", "s3cret")
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusBadRequest, res.Code, "read errors should not be reported as too large")
+	})
+
+	t.Run("ServeHTTP should ask concurrent duplicates to retry until the report is handled", func(t *testing.T) {
+		started, finish := make(chan struct{}), make(chan error)
+		h, err := NewWebhookHandler(&WebhookConfig{Secret: "s3cret"}, func(ctx context.Context, event DeliveryEvent) error {
+			close(started)
+			return <-finish
+		})
+		assert.NoError(t, err)
+
+		post := func() int {
+			req := httptest.NewRequest(http.MethodPost, "/dlr", strings.NewReader(report))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Jusibe-Secret", "s3cret")
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+			return res.Code
+		}
+
+		first := make(chan int)
+		go func() { first <- post() }()
+		<-started
+
+		assert.Equal(t, http.StatusConflict, post(), "duplicates should not be acked while the first is in flight")
+
+		finish <- errors.New("database unavailable")
+		assert.Equal(t, http.StatusInternalServerError, <-first)
+
+		h.handle = func(ctx context.Context, event DeliveryEvent) error { return nil }
+		assert.Equal(t, http.StatusOK, post(), "the retried report should be dispatched again")
+		assert.Equal(t, http.StatusOK, post(), "handled reports should be acked as duplicates")
+	})
+
+	t.Run("ServeHTTP should let failed reports be retried", func(t *testing.T) {
+		h, rec := newHandler(t, &WebhookConfig{Secret: "s3cret"})
+		rec.err = errors.New("database unavailable")
+
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		post := func() int {
+			req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(report))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Jusibe-Secret", "s3cret")
+			res, err := srv.Client().Do(req)
+			if !assert.NoError(t, err) {
+				return 0
+			}
+			res.Body.Close()
+			return res.StatusCode
+		}
+
+		assert.Equal(t, http.StatusInternalServerError, post())
+
+		rec.mu.Lock()
+		rec.err = nil
+		rec.mu.Unlock()
+
+		assert.Equal(t, http.StatusOK, post())
+		assert.Len(t, rec.events, 1)
+	})
+
+	t.Run("ServeHTTP should let reports be retried after handle panics", func(t *testing.T) {
+		var calls int
+		h, err := NewWebhookHandler(&WebhookConfig{Secret: "s3cret"}, func(ctx context.Context, event DeliveryEvent) error {
+			calls++
+			if calls == 1 {
+				panic("database driver bug")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+
+		// post recovers panics like net/http does
+		post := func() (code int) {
+			req := httptest.NewRequest(http.MethodPost, "/dlr", strings.NewReader(report))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Jusibe-Secret", "s3cret")
+			res := httptest.NewRecorder()
+			defer func() {
+				if recover() != nil {
+					code = http.StatusInternalServerError
+				}
+			}()
+			h.ServeHTTP(res, req)
+			return res.Code
+		}
+
+		assert.Equal(t, http.StatusInternalServerError, post())
+		assert.Equal(t, http.StatusOK, post(), "the claim should be released when handle panics")
+		assert.Equal(t, 2, calls)
+	})
+}
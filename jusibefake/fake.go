@@ -0,0 +1,425 @@
+/*
+Package jusibefake provides a stateful, in-memory implementation of jusibe.Client for unit tests.
+
+The fake keeps track of sent messages and the remaining SMS credits, and lets tests script delivery
+status transitions and inject failures without any HTTP traffic.
+
+Example Usage:
+
+	fake := jusibefake.New(10)
+
+	// Every message will be reported as Sent on the first status check and Delivered afterwards
+	fake.DeliveryScript = []jusibe.DeliveryStatus{jusibe.StatusSMSSent, jusibe.StatusSMSDelivered}
+
+	svc := NewNotificationService(fake) // accepts a jusibe.Client
+
+	...
+
+	sent := fake.Sent()
+*/
+package jusibefake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/segment"
+)
+
+// Operation identifies a jusibe.Client method for failure injection
+type Operation = jusibe.Operation
+
+const (
+	// OpSendSMS identifies jusibe.Client.SendSMS
+	OpSendSMS = jusibe.OpSendSMS
+
+	// OpSendBulkSMS identifies jusibe.Client.SendBulkSMS
+	OpSendBulkSMS = jusibe.OpSendBulkSMS
+
+	// OpCheckSMSCredits identifies jusibe.Client.CheckSMSCredits
+	OpCheckSMSCredits = jusibe.OpCheckSMSCredits
+
+	// OpCheckSMSDeliveryStatus identifies jusibe.Client.CheckSMSDeliveryStatus
+	OpCheckSMSDeliveryStatus = jusibe.OpCheckSMSDeliveryStatus
+
+	// OpCheckBulkSMSStatus identifies jusibe.Client.CheckBulkSMSStatus
+	OpCheckBulkSMSStatus = jusibe.OpCheckBulkSMSStatus
+)
+
+// Message is an SMS recorded by the fake
+type Message struct {
+	MessageID     string
+	BulkMessageID string
+	To            string
+	From          string
+	Body          string
+	Status        jusibe.DeliveryStatus
+	DateSent      time.Time
+	DateDelivered time.Time
+
+	script []jusibe.DeliveryStatus
+}
+
+// BulkMessage is a bulk SMS job recorded by the fake
+type BulkMessage struct {
+	BulkMessageID string
+	To            []string
+	From          string
+	Body          string
+	Status        jusibe.DeliveryStatus
+	Created       time.Time
+	Processed     time.Time
+
+	script []jusibe.DeliveryStatus
+}
+
+// Fake is an in-memory jusibe.Client
+// It is safe for concurrent use
+type Fake struct {
+	// DeliveryScript is the sequence of statuses reported by successive CheckSMSDeliveryStatus calls for new messages
+	// The last status sticks once the script is exhausted. Messages stay Sent when empty
+	DeliveryScript []jusibe.DeliveryStatus
+
+	// BulkScript is the sequence of statuses reported by successive CheckBulkSMSStatus calls for new bulk jobs
+	// The last status sticks once the script is exhausted. Bulk jobs stay Submitted when empty
+	BulkScript []jusibe.DeliveryStatus
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	mu       sync.Mutex
+	credits  int
+	nextID   int
+	messages map[string]*Message
+	bulk     map[string]*BulkMessage
+	sent     []*Message
+	failures map[Operation][]error
+}
+
+var _ jusibe.Client = (*Fake)(nil)
+
+// New creates a Fake holding the specified number of SMS credits
+func New(credits int) *Fake {
+	return &Fake{
+		Now:      time.Now,
+		credits:  credits,
+		messages: map[string]*Message{},
+		bulk:     map[string]*BulkMessage{},
+		failures: map[Operation][]error{},
+	}
+}
+
+// Fail queues errors returned by the next calls to op, one error per call
+// Return an *jusibe.APIError to simulate a specific http response code
+func (f *Fake) Fail(op Operation, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[op] = append(f.failures[op], errs...)
+}
+
+// SetCredits sets the remaining SMS credits
+func (f *Fake) SetCredits(credits int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.credits = credits
+}
+
+// Credits returns the remaining SMS credits
+func (f *Fake) Credits() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.credits
+}
+
+// SetDeliveryStatus scripts the statuses reported by the next CheckSMSDeliveryStatus calls for messageID
+func (f *Fake) SetDeliveryStatus(messageID string, statuses ...jusibe.DeliveryStatus) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, ok := f.messages[messageID]
+	if !ok {
+		return fmt.Errorf("jusibefake: unknown message id %q", messageID)
+	}
+	m.script = append([]jusibe.DeliveryStatus(nil), statuses...)
+
+	return nil
+}
+
+// SetBulkStatus scripts the statuses reported by the next CheckBulkSMSStatus calls for bulkMessageID
+func (f *Fake) SetBulkStatus(bulkMessageID string, statuses ...jusibe.DeliveryStatus) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.bulk[bulkMessageID]
+	if !ok {
+		return fmt.Errorf("jusibefake: unknown bulk message id %q", bulkMessageID)
+	}
+	b.script = append([]jusibe.DeliveryStatus(nil), statuses...)
+
+	return nil
+}
+
+// Sent returns a copy of every message sent through the fake, in order
+// Bulk SMS jobs are recorded as one message per recipient
+func (f *Fake) Sent() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sent := make([]Message, 0, len(f.sent))
+	for _, m := range f.sent {
+		cp := *m
+		cp.script = nil
+		sent = append(sent, cp)
+	}
+
+	return sent
+}
+
+// Reset forgets every sent message, queued failure and scripted status
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = map[string]*Message{}
+	f.bulk = map[string]*BulkMessage{}
+	f.sent = nil
+	f.failures = map[Operation][]error{}
+}
+
+// SendSMS records an SMS and charges one credit per SMS part
+func (f *Fake) SendSMS(ctx context.Context, to, from, message string) (*jusibe.SMSResponse, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(ctx, OpSendSMS); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	credits := segment.Calculate(message).Credits(1)
+	if err := f.charge(credits, "/send_sms"); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	m := f.record(to, from, message, "")
+
+	return &jusibe.SMSResponse{
+		Status:         m.Status,
+		MessageID:      m.MessageID,
+		SMSCreditsUsed: credits,
+	}, okResponse(), nil
+}
+
+// SendBulkSMS records a bulk SMS job for the comma separated recipients and charges one credit per SMS part per recipient
+func (f *Fake) SendBulkSMS(ctx context.Context, to, from, message string) (*jusibe.BulkSMSResponse, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(ctx, OpSendBulkSMS); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	recipients := jusibe.SplitRecipients(to)
+	if err := f.charge(segment.Calculate(message).Credits(len(recipients)), "/bulk/send_sms"); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	b := &BulkMessage{
+		BulkMessageID: f.newID("bulk"),
+		To:            recipients,
+		From:          from,
+		Body:          message,
+		Status:        jusibe.StatusBulkSMSSubmitted,
+		Created:       f.Now(),
+		script:        append([]jusibe.DeliveryStatus(nil), f.BulkScript...),
+	}
+	f.bulk[b.BulkMessageID] = b
+
+	for _, recipient := range recipients {
+		f.record(recipient, from, message, b.BulkMessageID)
+	}
+
+	return &jusibe.BulkSMSResponse{
+		Status:    b.Status,
+		MessageID: b.BulkMessageID,
+	}, okResponse(), nil
+}
+
+// CheckSMSCredits returns the remaining SMS credits
+func (f *Fake) CheckSMSCredits(ctx context.Context) (*jusibe.SMSCreditsResponse, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(ctx, OpCheckSMSCredits); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	return &jusibe.SMSCreditsResponse{SMSCredits: strconv.Itoa(f.credits)}, okResponse(), nil
+}
+
+// CheckSMSDeliveryStatus returns the next scripted status of messageID
+func (f *Fake) CheckSMSDeliveryStatus(ctx context.Context, messageID string) (*jusibe.SMSDeliveryResponse, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(ctx, OpCheckSMSDeliveryStatus); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	m, ok := f.messages[messageID]
+	if !ok {
+		err := notFound("/delivery_status")
+		return nil, errorResponse(err), err
+	}
+
+	if len(m.script) > 0 {
+		m.Status, m.script = m.script[0], m.script[1:]
+		if m.Status == jusibe.StatusSMSDelivered && m.DateDelivered.IsZero() {
+			m.DateDelivered = f.Now()
+		}
+	}
+
+	sds := &jusibe.SMSDeliveryResponse{
+		MessageID: m.MessageID,
+		Status:    m.Status,
+		DateSent:  jusibe.FormatTime(m.DateSent, nil),
+	}
+	if !m.DateDelivered.IsZero() {
+		sds.DateDelivered = jusibe.FormatTime(m.DateDelivered, nil)
+	}
+
+	return sds, okResponse(), nil
+}
+
+// CheckBulkSMSStatus returns the next scripted status of the bulk job
+func (f *Fake) CheckBulkSMSStatus(ctx context.Context, messageID string) (*jusibe.BulkSMSStatusResponse, *http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(ctx, OpCheckBulkSMSStatus); err != nil {
+		return nil, errorResponse(err), err
+	}
+
+	b, ok := f.bulk[messageID]
+	if !ok {
+		err := notFound("/bulk/status")
+		return nil, errorResponse(err), err
+	}
+
+	if len(b.script) > 0 {
+		b.Status, b.script = b.script[0], b.script[1:]
+		if b.Status != jusibe.StatusBulkSMSSubmitted && b.Processed.IsZero() {
+			b.Processed = f.Now()
+		}
+	}
+
+	unique := map[string]bool{}
+	for _, recipient := range b.To {
+		unique[recipient] = true
+	}
+
+	bss := &jusibe.BulkSMSStatusResponse{
+		BulkMessageID:       b.BulkMessageID,
+		Status:              b.Status,
+		Created:             jusibe.FormatTime(b.Created, nil),
+		TotalNumbers:        strconv.Itoa(len(b.To)),
+		TotalUniqueNumbers:  strconv.Itoa(len(unique)),
+		TotalValidNumbers:   strconv.Itoa(len(b.To)),
+		TotalInvalidNumbers: "0",
+	}
+	if !b.Processed.IsZero() {
+		bss.Processed = jusibe.FormatTime(b.Processed, nil)
+	}
+
+	return bss, okResponse(), nil
+}
+
+// fail returns the context error or the next queued failure for op
+// It must be called with f.mu held
+func (f *Fake) fail(ctx context.Context, op Operation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errs := f.failures[op]
+	if len(errs) == 0 {
+		return nil
+	}
+	f.failures[op] = errs[1:]
+
+	return errs[0]
+}
+
+// charge deducts credits, failing like Jusibe does when the balance is too low
+// It must be called with f.mu held
+func (f *Fake) charge(credits int, endpoint string) error {
+	if f.credits < credits {
+		return &jusibe.APIError{
+			StatusCode: http.StatusPaymentRequired,
+			Endpoint:   endpoint,
+			Response:   jusibe.ErrorResponse{Error: "Insufficient SMS credits"},
+		}
+	}
+	f.credits -= credits
+
+	return nil
+}
+
+// record stores a sent message
+// It must be called with f.mu held
+func (f *Fake) record(to, from, body, bulkMessageID string) *Message {
+	m := &Message{
+		MessageID:     f.newID("msg"),
+		BulkMessageID: bulkMessageID,
+		To:            to,
+		From:          from,
+		Body:          body,
+		Status:        jusibe.StatusSMSSent,
+		DateSent:      f.Now(),
+		script:        append([]jusibe.DeliveryStatus(nil), f.DeliveryScript...),
+	}
+	f.messages[m.MessageID] = m
+	f.sent = append(f.sent, m)
+
+	return m
+}
+
+// newID returns a unique, predictable id such as msg-1
+// It must be called with f.mu held
+func (f *Fake) newID(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-%d", prefix, f.nextID)
+}
+
+func notFound(endpoint string) *jusibe.APIError {
+	return &jusibe.APIError{
+		StatusCode: http.StatusNotFound,
+		Endpoint:   endpoint,
+		Response:   jusibe.ErrorResponse{Error: "Not found"},
+	}
+}
+
+func okResponse() *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}}
+}
+
+// errorResponse returns a *http.Response matching err, or nil when err didn't come from an http response
+func errorResponse(err error) *http.Response {
+	apiErr, ok := err.(*jusibe.APIError)
+	if !ok {
+		return nil
+	}
+
+	return &http.Response{
+		StatusCode: apiErr.StatusCode,
+		Status:     fmt.Sprintf("%d %s", apiErr.StatusCode, http.StatusText(apiErr.StatusCode)),
+		Header:     http.Header{},
+	}
+}
@@ -0,0 +1,144 @@
+package jusibefake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	t.Run("SendSMS should record messages and decrement credits", func(t *testing.T) {
+		fake := New(2)
+
+		s, res, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, jusibe.StatusSMSSent, s.Status)
+		assert.Equal(t, 1, s.SMSCreditsUsed)
+		assert.Equal(t, 1, fake.Credits())
+
+		sent := fake.Sent()
+		assert.Len(t, sent, 1)
+		assert.Equal(t, s.MessageID, sent[0].MessageID)
+		assert.Equal(t, "09001000101", sent[0].To)
+		assert.Equal(t, "test_user", sent[0].From)
+		assert.Equal(t, "Hello World!", sent[0].Body)
+
+		sc, _, err := fake.CheckSMSCredits(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "1", sc.SMSCredits)
+	})
+
+	t.Run("sends should fail with insufficient credits", func(t *testing.T) {
+		fake := New(1)
+
+		_, res, err := fake.SendBulkSMS(context.Background(), "09001000101,08030000000", "test_user", "Hello World!")
+		assert.True(t, errors.Is(err, jusibe.ErrInsufficientCredits))
+		assert.Equal(t, http.StatusPaymentRequired, res.StatusCode)
+		assert.Equal(t, 1, fake.Credits())
+		assert.Empty(t, fake.Sent())
+	})
+
+	t.Run("CheckSMSDeliveryStatus should follow the delivery script", func(t *testing.T) {
+		fake := New(10)
+		fake.DeliveryScript = []jusibe.DeliveryStatus{jusibe.StatusSMSSent, jusibe.StatusSMSDelivered}
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		ds, _, err := fake.CheckSMSDeliveryStatus(context.Background(), s.MessageID)
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusSMSSent, ds.Status)
+		assert.Empty(t, ds.DateDelivered)
+
+		for i := 0; i < 2; i++ {
+			ds, _, err = fake.CheckSMSDeliveryStatus(context.Background(), s.MessageID)
+			assert.NoError(t, err)
+			assert.Equal(t, jusibe.StatusSMSDelivered, ds.Status, "last status should stick")
+			assert.NotEmpty(t, ds.DateDelivered)
+		}
+
+		assert.NoError(t, fake.SetDeliveryStatus(s.MessageID, jusibe.StatusSMSRejected))
+		ds, _, _ = fake.CheckSMSDeliveryStatus(context.Background(), s.MessageID)
+		assert.Equal(t, jusibe.StatusSMSRejected, ds.Status)
+
+		assert.Error(t, fake.SetDeliveryStatus("unknown", jusibe.StatusSMSDelivered))
+
+		_, _, err = fake.CheckSMSDeliveryStatus(context.Background(), "unknown")
+		assert.True(t, errors.Is(err, jusibe.ErrNotFound))
+	})
+
+	t.Run("timestamps should round-trip whatever the timezone of Now", func(t *testing.T) {
+		now := time.Date(2020, 1, 1, 23, 30, 0, 0, time.FixedZone("PST", -8*60*60))
+		fake := New(10)
+		fake.Now = func() time.Time { return now }
+		fake.DeliveryScript = []jusibe.DeliveryStatus{jusibe.StatusSMSDelivered}
+
+		s, _, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		ds, _, err := fake.CheckSMSDeliveryStatus(context.Background(), s.MessageID)
+		assert.NoError(t, err)
+
+		sentAt, err := ds.SentAt()
+		assert.NoError(t, err)
+		assert.True(t, now.Equal(sentAt), "%s != %s", now, sentAt)
+
+		deliveredAt, err := ds.DeliveredAt()
+		assert.NoError(t, err)
+		assert.True(t, now.Equal(deliveredAt), "%s != %s", now, deliveredAt)
+	})
+
+	t.Run("SendBulkSMS should record one message per recipient", func(t *testing.T) {
+		fake := New(10)
+
+		bs, _, err := fake.SendBulkSMS(context.Background(), "09001000101, 08030000000,09050000000", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusBulkSMSSubmitted, bs.Status)
+		assert.Equal(t, 7, fake.Credits())
+		assert.Len(t, fake.Sent(), 3)
+
+		assert.NoError(t, fake.SetBulkStatus(bs.MessageID, jusibe.StatusBulkSMSProcessing, jusibe.StatusBulkSMSCompleted))
+
+		status, _, err := fake.CheckBulkSMSStatus(context.Background(), bs.MessageID)
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusBulkSMSProcessing, status.Status)
+		assert.Equal(t, "3", status.TotalNumbers)
+
+		status, _, _ = fake.CheckBulkSMSStatus(context.Background(), bs.MessageID)
+		assert.Equal(t, jusibe.StatusBulkSMSCompleted, status.Status)
+		assert.NotEmpty(t, status.Processed)
+	})
+
+	t.Run("Fail should inject errors once per call", func(t *testing.T) {
+		fake := New(10)
+		boom := errors.New("boom")
+		fake.Fail(OpSendSMS, boom, &jusibe.APIError{StatusCode: http.StatusServiceUnavailable})
+
+		_, res, err := fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.Equal(t, boom, err)
+		assert.Nil(t, res)
+
+		_, res, err = fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.True(t, errors.Is(err, jusibe.ErrServer))
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+		_, _, err = fake.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.Equal(t, 9, fake.Credits())
+	})
+
+	t.Run("calls should fail when context is done", func(t *testing.T) {
+		fake := New(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := fake.CheckSMSCredits(ctx)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
@@ -0,0 +1,467 @@
+/*
+Package jusibetest provides a local fake Jusibe HTTP server for integration tests.
+
+The server implements the /send_sms, /bulk/send_sms, /get_credits, /delivery_status and /bulk/status
+endpoints with Basic Auth checking and credit accounting (one credit per SMS part per recipient), so the real jusibe client can be exercised
+end-to-end without network access.
+
+Example Usage:
+
+	srv := jusibetest.NewServer("public_key", "access_token", 100)
+	defer srv.Close()
+
+	j, err := jusibe.New(srv.Config())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	smsResponse, _, err := j.SendSMS(context.Background(), "08030000000", "Azeez", "Hello World")
+*/
+package jusibetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/segment"
+)
+
+// BasePath is the path every endpoint is served under, mirroring https://jusibe.com/smsapi
+const BasePath = "/smsapi"
+
+// Message is an SMS accepted by the server
+type Message struct {
+	MessageID     string
+	BulkMessageID string
+	To            string
+	From          string
+	Body          string
+	Status        jusibe.DeliveryStatus
+	DateSent      time.Time
+	DateDelivered time.Time
+}
+
+type bulkMessage struct {
+	bulkMessageID string
+	to            []string
+	status        jusibe.DeliveryStatus
+	created       time.Time
+	processed     time.Time
+}
+
+type failure struct {
+	statusCode int
+	body       string
+}
+
+// Server is a fake Jusibe API server
+// It is safe for concurrent use
+type Server struct {
+	*httptest.Server
+
+	publicKey   string
+	accessToken string
+
+	mu       sync.Mutex
+	credits  int
+	nextID   int
+	latency  time.Duration
+	messages map[string]*Message
+	bulk     map[string]*bulkMessage
+	sent     []*Message
+	failures map[string][]failure
+	requests int
+}
+
+// NewServer starts a fake Jusibe server which accepts the publicKey and accessToken pair and holds the specified SMS credits
+// The caller should call Close when finished, to shut it down
+func NewServer(publicKey, accessToken string, credits int) *Server {
+	s := &Server{
+		publicKey:   publicKey,
+		accessToken: accessToken,
+		credits:     credits,
+		messages:    map[string]*Message{},
+		bulk:        map[string]*bulkMessage{},
+		failures:    map[string][]failure{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(BasePath+"/send_sms", s.handle(http.MethodPost, s.sendSMS))
+	mux.HandleFunc(BasePath+"/bulk/send_sms", s.handle(http.MethodPost, s.sendBulkSMS))
+	mux.HandleFunc(BasePath+"/get_credits", s.handle(http.MethodGet, s.getCredits))
+	mux.HandleFunc(BasePath+"/delivery_status", s.handle(http.MethodGet, s.deliveryStatus))
+	mux.HandleFunc(BasePath+"/bulk/status", s.handle(http.MethodGet, s.bulkStatus))
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// BaseURL returns the base URL to set on jusibe.Config
+func (s *Server) BaseURL() string {
+	return s.URL + BasePath
+}
+
+// Config returns a *jusibe.Config pointing at the server with valid credentials
+func (s *Server) Config() *jusibe.Config {
+	return &jusibe.Config{
+		PublicKey:   s.publicKey,
+		AccessToken: s.accessToken,
+		BaseURL:     s.BaseURL(),
+	}
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// FailNext makes the next request to endpoint (e.g /send_sms) respond with statusCode and body
+// Calls queue up, one failure per request
+func (s *Server) FailNext(endpoint string, statusCode int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[endpoint] = append(s.failures[endpoint], failure{statusCode: statusCode, body: body})
+}
+
+// SetCredits sets the remaining SMS credits
+func (s *Server) SetCredits(credits int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.credits = credits
+}
+
+// Credits returns the remaining SMS credits
+func (s *Server) Credits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.credits
+}
+
+// SetDeliveryStatus sets the status reported by /delivery_status for messageID
+func (s *Server) SetDeliveryStatus(messageID string, status jusibe.DeliveryStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.messages[messageID]
+	if !ok {
+		return fmt.Errorf("jusibetest: unknown message id %q", messageID)
+	}
+
+	m.Status = status
+	if status == jusibe.StatusSMSDelivered {
+		m.DateDelivered = time.Now()
+	}
+
+	return nil
+}
+
+// SetBulkStatus sets the status reported by /bulk/status for bulkMessageID
+func (s *Server) SetBulkStatus(bulkMessageID string, status jusibe.DeliveryStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bulk[bulkMessageID]
+	if !ok {
+		return fmt.Errorf("jusibetest: unknown bulk message id %q", bulkMessageID)
+	}
+
+	b.status = status
+	if status != jusibe.StatusBulkSMSSubmitted {
+		b.processed = time.Now()
+	}
+
+	return nil
+}
+
+// Sent returns a copy of every message accepted by the server, in order
+// Bulk SMS jobs are recorded as one message per recipient
+func (s *Server) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := make([]Message, 0, len(s.sent))
+	for _, m := range s.sent {
+		sent = append(sent, *m)
+	}
+
+	return sent
+}
+
+// Requests returns the number of requests received, including rejected ones
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// handle wraps an endpoint handler with method, latency, failure injection and Basic Auth checks
+func (s *Server) handle(method string, h func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		latency := s.latency
+		s.mu.Unlock()
+
+		if latency > 0 {
+			timer := time.NewTimer(latency)
+			select {
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		if f, ok := s.nextFailure(strings.TrimPrefix(r.URL.Path, BasePath)); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.statusCode)
+			fmt.Fprint(w, f.body)
+			return
+		}
+
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		publicKey, accessToken, ok := r.BasicAuth()
+		if !ok || publicKey != s.publicKey || accessToken != s.accessToken {
+			writeError(w, http.StatusUnauthorized, "Invalid API Key!")
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+func (s *Server) nextFailure(endpoint string) (f failure, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.failures[endpoint]
+	if len(failures) == 0 {
+		return
+	}
+	s.failures[endpoint] = failures[1:]
+
+	return failures[0], true
+}
+
+func (s *Server) sendSMS(w http.ResponseWriter, r *http.Request) {
+	to, from, body, ok := sendParams(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credits := segment.Calculate(body).Credits(1)
+	if !s.charge(w, credits) {
+		return
+	}
+
+	m := s.record(to, from, body, "")
+
+	writeJSON(w, http.StatusOK, &jusibe.SMSResponse{
+		Status:         m.Status,
+		MessageID:      m.MessageID,
+		SMSCreditsUsed: credits,
+	})
+}
+
+func (s *Server) sendBulkSMS(w http.ResponseWriter, r *http.Request) {
+	to, from, body, ok := sendParams(w, r)
+	if !ok {
+		return
+	}
+
+	recipients := jusibe.SplitRecipients(to)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.charge(w, segment.Calculate(body).Credits(len(recipients))) {
+		return
+	}
+
+	b := &bulkMessage{
+		bulkMessageID: s.newID("bulk"),
+		to:            recipients,
+		status:        jusibe.StatusBulkSMSSubmitted,
+		created:       time.Now(),
+	}
+	s.bulk[b.bulkMessageID] = b
+
+	for _, recipient := range recipients {
+		s.record(recipient, from, body, b.bulkMessageID)
+	}
+
+	writeJSON(w, http.StatusOK, &jusibe.BulkSMSResponse{
+		Status:    b.status,
+		MessageID: b.bulkMessageID,
+	})
+}
+
+func (s *Server) getCredits(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, &jusibe.SMSCreditsResponse{SMSCredits: strconv.Itoa(s.credits)})
+}
+
+func (s *Server) deliveryStatus(w http.ResponseWriter, r *http.Request) {
+	messageID := r.FormValue("message_id")
+	if messageID == "" {
+		writeInvalidParameters(w, map[string]string{"message_id": "The message id field is required."})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.messages[messageID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Message not found")
+		return
+	}
+
+	sds := &jusibe.SMSDeliveryResponse{
+		MessageID: m.MessageID,
+		Status:    m.Status,
+		DateSent:  jusibe.FormatTime(m.DateSent, nil),
+	}
+	if !m.DateDelivered.IsZero() {
+		sds.DateDelivered = jusibe.FormatTime(m.DateDelivered, nil)
+	}
+
+	writeJSON(w, http.StatusOK, sds)
+}
+
+func (s *Server) bulkStatus(w http.ResponseWriter, r *http.Request) {
+	bulkMessageID := r.FormValue("bulk_message_id")
+	if bulkMessageID == "" {
+		writeInvalidParameters(w, map[string]string{"bulk_message_id": "The bulk message id field is required."})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bulk[bulkMessageID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Bulk message not found")
+		return
+	}
+
+	unique := map[string]bool{}
+	for _, recipient := range b.to {
+		unique[recipient] = true
+	}
+
+	bss := &jusibe.BulkSMSStatusResponse{
+		BulkMessageID:       b.bulkMessageID,
+		Status:              b.status,
+		Created:             jusibe.FormatTime(b.created, nil),
+		TotalNumbers:        strconv.Itoa(len(b.to)),
+		TotalUniqueNumbers:  strconv.Itoa(len(unique)),
+		TotalValidNumbers:   strconv.Itoa(len(b.to)),
+		TotalInvalidNumbers: "0",
+	}
+	if !b.processed.IsZero() {
+		bss.Processed = jusibe.FormatTime(b.processed, nil)
+	}
+
+	writeJSON(w, http.StatusOK, bss)
+}
+
+// charge deducts credits, writing an error response when the balance is too low
+// It must be called with s.mu held
+func (s *Server) charge(w http.ResponseWriter, credits int) bool {
+	if s.credits < credits {
+		writeError(w, http.StatusPaymentRequired, "Insufficient SMS credits")
+		return false
+	}
+	s.credits -= credits
+
+	return true
+}
+
+// record stores an accepted message
+// It must be called with s.mu held
+func (s *Server) record(to, from, body, bulkMessageID string) *Message {
+	m := &Message{
+		MessageID:     s.newID("msg"),
+		BulkMessageID: bulkMessageID,
+		To:            to,
+		From:          from,
+		Body:          body,
+		Status:        jusibe.StatusSMSSent,
+		DateSent:      time.Now(),
+	}
+	s.messages[m.MessageID] = m
+	s.sent = append(s.sent, m)
+
+	return m
+}
+
+// newID returns a unique id such as msg-1
+// It must be called with s.mu held
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+// sendParams reads and validates the to, from and message parameters, writing an error response when invalid
+func sendParams(w http.ResponseWriter, r *http.Request) (to, from, message string, ok bool) {
+	to, from, message = r.FormValue("to"), r.FormValue("from"), r.FormValue("message")
+
+	invalid := map[string]string{}
+	if to == "" {
+		invalid["to"] = "The to field is required."
+	}
+	if from == "" {
+		invalid["from"] = "The from field is required."
+	} else if len(from) > 11 {
+		invalid["from"] = "The from may not be greater than 11 characters."
+	}
+	if message == "" {
+		invalid["message"] = "The message field is required."
+	}
+
+	if len(invalid) > 0 {
+		writeInvalidParameters(w, invalid)
+		return
+	}
+
+	return to, from, message, true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, &jusibe.ErrorResponse{Error: message})
+}
+
+func writeInvalidParameters(w http.ResponseWriter, invalid map[string]string) {
+	writeJSON(w, http.StatusBadRequest, &jusibe.ErrorResponse{InvalidParameters: invalid})
+}
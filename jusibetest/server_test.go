@@ -0,0 +1,161 @@
+package jusibetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("client should send SMS and check delivery status end-to-end", func(t *testing.T) {
+		srv := NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		j, err := jusibe.New(srv.Config())
+		assert.NoError(t, err)
+
+		s, res, err := j.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, jusibe.StatusSMSSent, s.Status)
+		assert.Equal(t, 1, s.SMSCreditsUsed)
+		assert.Equal(t, 9, srv.Credits())
+
+		sent := srv.Sent()
+		assert.Len(t, sent, 1)
+		assert.Equal(t, "09001000101", sent[0].To)
+		assert.Equal(t, "Hello World!", sent[0].Body)
+
+		assert.NoError(t, srv.SetDeliveryStatus(s.MessageID, jusibe.StatusSMSDelivered))
+
+		ds, _, err := j.CheckSMSDeliveryStatus(context.Background(), s.MessageID)
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusSMSDelivered, ds.Status)
+		sentAt, err := ds.SentAt()
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), sentAt, time.Minute, "timestamps should round-trip whatever the local timezone")
+		deliveredAt, err := ds.DeliveredAt()
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), deliveredAt, time.Minute)
+
+		sc, _, err := j.CheckSMSCredits(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "9", sc.SMSCredits)
+	})
+
+	t.Run("client should send bulk SMS and check bulk status end-to-end", func(t *testing.T) {
+		srv := NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		j, err := jusibe.New(srv.Config())
+		assert.NoError(t, err)
+
+		bs, _, err := j.SendBulkSMS(context.Background(), "09001000101,08030000000,09050000000", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusBulkSMSSubmitted, bs.Status)
+		assert.Equal(t, 7, srv.Credits())
+
+		assert.NoError(t, srv.SetBulkStatus(bs.MessageID, jusibe.StatusBulkSMSCompleted))
+
+		status, _, err := j.CheckBulkSMSStatus(context.Background(), bs.MessageID)
+		assert.NoError(t, err)
+		assert.Equal(t, jusibe.StatusBulkSMSCompleted, status.Status)
+		assert.Equal(t, "3", status.TotalNumbers)
+		createdAt, err := status.CreatedAt()
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), createdAt, time.Minute)
+		processedAt, err := status.ProcessedAt()
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), processedAt, time.Minute)
+	})
+
+	t.Run("server should reject invalid credentials", func(t *testing.T) {
+		srv := NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		cfg := srv.Config()
+		cfg.AccessToken = "wrong_access_token"
+		j, err := jusibe.New(cfg)
+		assert.NoError(t, err)
+
+		_, _, err = j.CheckSMSCredits(context.Background())
+		assert.True(t, errors.Is(err, jusibe.ErrUnauthorized))
+	})
+
+	t.Run("server should account credits and validate parameters", func(t *testing.T) {
+		srv := NewServer("some_public_key", "some_access_token", 1)
+		defer srv.Close()
+
+		j, err := jusibe.New(srv.Config())
+		assert.NoError(t, err)
+
+		_, _, err = j.SendBulkSMS(context.Background(), "09001000101,08030000000", "test_user", "Hello World!")
+		assert.True(t, errors.Is(err, jusibe.ErrInsufficientCredits))
+
+		_, _, err = j.SendSMS(context.Background(), "", "test_user", "Hello World!")
+		assert.True(t, errors.Is(err, jusibe.ErrInvalidRecipient))
+
+		_, _, err = j.CheckSMSDeliveryStatus(context.Background(), "unknown")
+		assert.True(t, errors.Is(err, jusibe.ErrNotFound))
+
+		assert.Equal(t, 1, srv.Credits())
+		assert.Empty(t, srv.Sent())
+	})
+
+	t.Run("server and client should agree on numeric sender ids", func(t *testing.T) {
+		srv := NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		j, err := jusibe.New(srv.Config())
+		assert.NoError(t, err)
+
+		_, _, err = j.SendSMS(context.Background(), "09001000101", "08030000000", "Hello World!")
+		assert.NoError(t, err)
+
+		_, res, err := j.SendSMS(context.Background(), "09001000101", "080300000001", "Hello World!")
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, jusibe.ErrInvalidSender))
+		assert.Equal(t, 1, srv.Requests(), "the client should reject 12 digit sender ids before any request")
+
+		form := url.Values{"to": {"09001000101"}, "from": {"080300000001"}, "message": {"Hello World!"}}
+		req, _ := http.NewRequest(http.MethodPost, srv.BaseURL()+"/send_sms", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("some_public_key", "some_access_token")
+		raw, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err) {
+			raw.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, raw.StatusCode, "the server should reject 12 digit sender ids too")
+		}
+	})
+
+	t.Run("server should inject failures and latency", func(t *testing.T) {
+		srv := NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		cfg := srv.Config()
+		cfg.RetryPolicy = &jusibe.RetryPolicy{InitialBackoff: time.Millisecond}
+		j, err := jusibe.New(cfg)
+		assert.NoError(t, err)
+
+		srv.FailNext("/get_credits", http.StatusServiceUnavailable, `{"error": "Service unavailable"}`)
+
+		sc, _, err := j.CheckSMSCredits(context.Background())
+		assert.NoError(t, err, "should recover with retries")
+		assert.Equal(t, "10", sc.SMSCredits)
+		assert.Equal(t, 2, srv.Requests())
+
+		srv.SetLatency(time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, _, err = j.CheckSMSCredits(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,230 @@
+/*
+Package mailmerge sends personalized SMS to many recipients from a text/template.
+
+Every recipient gets the template rendered with its own data. Recipients whose rendered messages are
+identical are sent together with SendBulkSMS, and the remaining recipients are sent one by one with SendSMS.
+Preview renders the messages without sending them, along with their segment estimates.
+
+Example Usage:
+
+	t, err := mailmerge.Parse("MyShop", "Hi {{.FirstName}}, your order {{.OrderID}} has shipped")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	recipients := []mailmerge.Recipient{
+		{To: "08030000000", Data: map[string]string{"FirstName": "Ada", "OrderID": "1001"}},
+		{To: "08050000000", Data: map[string]string{"FirstName": "Tunde", "OrderID": "1002"}},
+	}
+
+	// Dry run
+	for _, m := range t.Preview(recipients) {
+		fmt.Println(m.To, m.Body, m.Estimate.Segments, m.Err)
+	}
+
+	result, err := t.Send(context.Background(), j, recipients, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(len(result.Failed()), "messages were not sent")
+*/
+package mailmerge
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/segment"
+)
+
+// Recipient is a single recipient along with the data its message is rendered with
+type Recipient struct {
+	To string
+
+	// Data is passed to the template, e.g a struct or a map[string]string
+	Data interface{}
+}
+
+// Message is the rendered message of a single recipient
+type Message struct {
+	To   string
+	Body string
+
+	// Estimate is the segment estimate of Body
+	Estimate segment.Estimate
+
+	// MessageID is set when the message was sent with SendSMS
+	MessageID string
+
+	// BulkMessageID is set when the message was sent with SendBulkSMS along with identical messages
+	BulkMessageID string
+
+	// Err is the rendering error, or the error of the request the message was sent in
+	Err error
+}
+
+// Result is the result of Template.Send
+type Result struct {
+	// Messages has a message per recipient, in the order recipients were given
+	Messages []Message
+}
+
+// Failed returns the messages which were not sent
+func (r *Result) Failed() []Message {
+	failed := []Message{}
+	for _, m := range r.Messages {
+		if m.Err != nil {
+			failed = append(failed, m)
+		}
+	}
+	return failed
+}
+
+// Template is a parsed message template
+type Template struct {
+	from string
+	tmpl *template.Template
+}
+
+// Parse parses text with text/template
+// Rendering fails for recipients whose data lacks a key used by the template
+// It returns an error when from fails jusibe.ValidateSenderID
+func Parse(from, text string) (t *Template, err error) {
+	if err = jusibe.ValidateSenderID(from); err != nil {
+		return
+	}
+
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return
+	}
+
+	t = &Template{from: from, tmpl: tmpl}
+
+	return
+}
+
+// Preview renders the message of every recipient without sending anything
+func (t *Template) Preview(recipients []Recipient) []Message {
+	messages := make([]Message, len(recipients))
+	for i, r := range recipients {
+		messages[i] = t.render(r)
+	}
+	return messages
+}
+
+// Send renders the message of every recipient and sends them with c
+// Identical messages are sent with jusibe.DispatchBulk using opts, and the others with SendSMS
+// Failures are reported per message in the result. It only returns an error when ctx is done, in which case
+// the messages which were not sent report that error too
+func (t *Template) Send(ctx context.Context, c jusibe.Client, recipients []Recipient, opts *jusibe.DispatchOptions) (result *Result, err error) {
+	result = &Result{Messages: t.Preview(recipients)}
+
+	// groups maps every rendered body to the indexes of the messages sharing it, in order of first appearance
+	groups := map[string][]int{}
+	bodies := []string{}
+	for i, m := range result.Messages {
+		if m.Err != nil {
+			continue
+		}
+		if _, ok := groups[m.Body]; !ok {
+			bodies = append(bodies, m.Body)
+		}
+		groups[m.Body] = append(groups[m.Body], i)
+	}
+
+	for b, body := range bodies {
+		if err = ctx.Err(); err != nil {
+			// Messages which were never sent must not be reported as sent
+			for _, unsent := range bodies[b:] {
+				for _, i := range groups[unsent] {
+					result.Messages[i].Err = err
+				}
+			}
+			return
+		}
+
+		indexes := groups[body]
+		if len(indexes) == 1 {
+			m := &result.Messages[indexes[0]]
+			var ssr *jusibe.SMSResponse
+			if ssr, _, m.Err = c.SendSMS(ctx, m.To, t.from, body); m.Err == nil {
+				m.MessageID = ssr.MessageID
+			}
+			continue
+		}
+
+		to := make([]string, len(indexes))
+		for n, i := range indexes {
+			to[n] = result.Messages[i].To
+		}
+
+		dispatch, dispatchErr := jusibe.DispatchBulk(ctx, c, &jusibe.BulkSMSRequest{To: to, From: t.from, Message: body}, opts)
+		for _, i := range indexes {
+			m := &result.Messages[i]
+			if dispatchErr != nil {
+				m.Err = dispatchErr
+				continue
+			}
+			m.BulkMessageID, m.Err = dispatch.Recipients[m.To].BulkMessageID, dispatch.Recipients[m.To].Err
+		}
+	}
+
+	return
+}
+
+// render renders the message of r
+func (t *Template) render(r Recipient) (m Message) {
+	m.To = r.To
+
+	var b strings.Builder
+	if m.Err = t.tmpl.Execute(&b, r.Data); m.Err != nil {
+		return
+	}
+
+	m.Body = b.String()
+	m.Estimate = segment.Calculate(m.Body)
+	if m.Body == "" {
+		m.Err = jusibe.ErrEmptyMessage
+	}
+
+	return
+}
+
+// ReadCSV reads recipients from CSV with a header row
+// The toColumn column holds the phone numbers, and every row is passed to the template as a map[string]string keyed by column name
+func ReadCSV(r io.Reader, toColumn string) (recipients []Recipient, err error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("mailmerge: missing CSV header row")
+	}
+
+	header, toIndex := rows[0], -1
+	for i, name := range header {
+		if name == toColumn {
+			toIndex = i
+		}
+	}
+	if toIndex < 0 {
+		return nil, fmt.Errorf("mailmerge: missing %q CSV column", toColumn)
+	}
+
+	for _, row := range rows[1:] {
+		data := make(map[string]string, len(header))
+		for i, name := range header {
+			data[name] = row[i]
+		}
+		recipients = append(recipients, Recipient{To: row[toIndex], Data: data})
+	}
+
+	return
+}
@@ -0,0 +1,141 @@
+package mailmerge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibefake"
+	"github.com/azeezolaniran2016/jusibe-go/segment"
+	"github.com/stretchr/testify/assert"
+)
+
+// cancellingClient cancels the send context after the first SendSMS
+type cancellingClient struct {
+	*jusibefake.Fake
+	cancel context.CancelFunc
+}
+
+func (c *cancellingClient) SendSMS(ctx context.Context, to, from, message string) (*jusibe.SMSResponse, *http.Response, error) {
+	defer c.cancel()
+	return c.Fake.SendSMS(ctx, to, from, message)
+}
+
+func TestMailMerge(t *testing.T) {
+	type order struct {
+		FirstName string
+		OrderID   string
+	}
+
+	t.Run("Parse should validate the sender id and template", func(t *testing.T) {
+		_, err := Parse("Café", "Hello")
+		assert.True(t, errors.Is(err, jusibe.ErrInvalidSender))
+
+		_, err = Parse("MyShop", "Hi {{.FirstName")
+		assert.Error(t, err)
+	})
+
+	t.Run("Preview should render every recipient without sending", func(t *testing.T) {
+		tmpl, err := Parse("MyShop", "Hi {{.FirstName}}, your order {{.OrderID}} has shipped 📦")
+		assert.NoError(t, err)
+
+		messages := tmpl.Preview([]Recipient{
+			{To: "08030000000", Data: order{FirstName: "Ada", OrderID: "1001"}},
+			{To: "08050000000", Data: map[string]string{"FirstName": "Tunde"}},
+		})
+
+		assert.Len(t, messages, 2)
+		assert.NoError(t, messages[0].Err)
+		assert.Equal(t, "Hi Ada, your order 1001 has shipped 📦", messages[0].Body)
+		assert.Equal(t, segment.UCS2, messages[0].Estimate.Encoding)
+		assert.Equal(t, 1, messages[0].Estimate.Segments)
+
+		assert.Error(t, messages[1].Err, "should fail when data lacks a key")
+		assert.Empty(t, messages[1].Body)
+	})
+
+	t.Run("Send should group identical messages into bulk sends", func(t *testing.T) {
+		fake := jusibefake.New(100)
+
+		tmpl, err := Parse("MyShop", "Hi {{.FirstName}}, your order has shipped")
+		assert.NoError(t, err)
+
+		result, err := tmpl.Send(context.Background(), fake, []Recipient{
+			{To: "08030000000", Data: order{FirstName: "Ada"}},
+			{To: "08050000000", Data: order{FirstName: "Tunde"}},
+			{To: "08090000000", Data: order{FirstName: "Ada"}},
+			{To: "08070000000", Data: nil},
+		}, nil)
+		assert.NoError(t, err)
+
+		assert.Len(t, result.Messages, 4)
+		assert.NotEmpty(t, result.Messages[0].BulkMessageID)
+		assert.Equal(t, result.Messages[0].BulkMessageID, result.Messages[2].BulkMessageID)
+		assert.Empty(t, result.Messages[0].MessageID)
+
+		assert.NotEmpty(t, result.Messages[1].MessageID)
+		assert.Empty(t, result.Messages[1].BulkMessageID)
+
+		failed := result.Failed()
+		assert.Len(t, failed, 1)
+		assert.Equal(t, "08070000000", failed[0].To)
+
+		assert.Len(t, fake.Sent(), 3)
+	})
+
+	t.Run("Send should report send failures per message", func(t *testing.T) {
+		fake := jusibefake.New(100)
+		fake.Fail(jusibefake.OpSendSMS, &jusibe.APIError{StatusCode: http.StatusUnauthorized})
+
+		tmpl, err := Parse("MyShop", "Hi {{.FirstName}}")
+		assert.NoError(t, err)
+
+		result, err := tmpl.Send(context.Background(), fake, []Recipient{{To: "08030000000", Data: order{FirstName: "Ada"}}}, nil)
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(result.Messages[0].Err, jusibe.ErrUnauthorized))
+	})
+
+	t.Run("Send should report unsent messages when ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fake := jusibefake.New(100)
+		client := &cancellingClient{Fake: fake, cancel: cancel}
+
+		tmpl, err := Parse("MyShop", "Hi {{.FirstName}}")
+		assert.NoError(t, err)
+
+		result, err := tmpl.Send(ctx, client, []Recipient{
+			{To: "08030000000", Data: order{FirstName: "Ada"}},
+			{To: "08050000000", Data: order{FirstName: "Tunde"}},
+			{To: "08090000000", Data: order{FirstName: "Tunde"}},
+			{To: "08070000000", Data: order{FirstName: "Bola"}},
+		}, nil)
+		assert.Equal(t, context.Canceled, err)
+
+		assert.NoError(t, result.Messages[0].Err)
+		assert.NotEmpty(t, result.Messages[0].MessageID)
+
+		failed := result.Failed()
+		assert.Len(t, failed, 3)
+		for _, m := range failed {
+			assert.Equal(t, context.Canceled, m.Err, m.To)
+		}
+		assert.Len(t, fake.Sent(), 1)
+	})
+
+	t.Run("ReadCSV should key row data by column name", func(t *testing.T) {
+		recipients, err := ReadCSV(strings.NewReader("phone,FirstName\n08030000000,Ada\n08050000000,Tunde\n"), "phone")
+		assert.NoError(t, err)
+		assert.Equal(t, []Recipient{
+			{To: "08030000000", Data: map[string]string{"phone": "08030000000", "FirstName": "Ada"}},
+			{To: "08050000000", Data: map[string]string{"phone": "08050000000", "FirstName": "Tunde"}},
+		}, recipients)
+
+		_, err = ReadCSV(strings.NewReader("number,FirstName\n"), "phone")
+		assert.EqualError(t, err, `mailmerge: missing "phone" CSV column`)
+	})
+}
@@ -0,0 +1,229 @@
+/*
+Package metrics collects Jusibe client metrics and exposes them in the Prometheus text format.
+
+A Collector implements jusibe.Metrics and http.Handler, so it can be scraped by Prometheus without any
+other dependency. It exposes:
+
+	jusibe_requests_total{operation, status_class}      counter of calls by http status class (2xx, 4xx, 5xx or error)
+	jusibe_request_duration_seconds{operation}          histogram of call latencies
+	jusibe_sms_credits_used_total                       counter of SMSCreditsUsed reported by SendSMS
+	jusibe_sms_credits                                  gauge of the last SMS credits balance seen by CheckSMSCredits
+
+Example Usage:
+
+	collector := metrics.NewCollector()
+
+	j, err := jusibe.New(&jusibe.Config{
+		PublicKey:   os.Getenv("JUSIBE_PUBLIC_KEY"),
+		AccessToken: os.Getenv("JUSIBE_ACCESS_TOKEN"),
+		Metrics:     collector,
+	})
+
+	http.Handle("/metrics", collector)
+*/
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+)
+
+// DefaultBuckets are the default latency histogram buckets, in seconds
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// contentType is the Prometheus text exposition format content type
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type requestKey struct {
+	operation   jusibe.Operation
+	statusClass string
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Collector aggregates jusibe.CallObservation values
+// Create a Collector with NewCollector
+type Collector struct {
+	buckets []float64
+
+	mu          sync.Mutex
+	requests    map[requestKey]uint64
+	durations   map[jusibe.Operation]*histogram
+	creditsUsed uint64
+	credits     float64
+	hasCredits  bool
+}
+
+var _ jusibe.Metrics = (*Collector)(nil)
+
+// NewCollector creates a Collector with the specified latency histogram buckets, in seconds
+// It uses DefaultBuckets when no bucket is specified
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &Collector{
+		buckets:   sorted,
+		requests:  map[requestKey]uint64{},
+		durations: map[jusibe.Operation]*histogram{},
+	}
+}
+
+// ObserveCall implements jusibe.Metrics
+func (c *Collector) ObserveCall(ctx context.Context, o jusibe.CallObservation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests[requestKey{operation: o.Operation, statusClass: callClass(o)}]++
+
+	h, ok := c.durations[o.Operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[o.Operation] = h
+	}
+
+	seconds := o.Latency.Seconds()
+	for i, upperBound := range c.buckets {
+		if seconds <= upperBound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+
+	if o.CreditsUsed > 0 {
+		c.creditsUsed += uint64(o.CreditsUsed)
+	}
+	if o.HasCredits {
+		c.credits, c.hasCredits = o.Credits, true
+	}
+}
+
+// callClass returns the status class of o
+// Calls failing for any reason but an http error response, e.g an undecodable 200 response, are classed as "error"
+// regardless of their status code
+func callClass(o jusibe.CallObservation) string {
+	var apiErr *jusibe.APIError
+	if o.Err != nil && !errors.As(o.Err, &apiErr) {
+		return "error"
+	}
+	return StatusClass(o.StatusCode)
+}
+
+// StatusClass returns the class of an http response code, e.g 2xx, or "error" when no response was received
+func StatusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format to w
+func (c *Collector) WriteTo(w io.Writer) (n int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	requestKeys := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		requestKeys = append(requestKeys, k)
+	}
+	sort.Slice(requestKeys, func(i, k int) bool {
+		if requestKeys[i].operation != requestKeys[k].operation {
+			return requestKeys[i].operation < requestKeys[k].operation
+		}
+		return requestKeys[i].statusClass < requestKeys[k].statusClass
+	})
+
+	cw.printf("# HELP jusibe_requests_total Jusibe API calls by operation and http status class.\n")
+	cw.printf("# TYPE jusibe_requests_total counter\n")
+	for _, k := range requestKeys {
+		cw.printf("jusibe_requests_total{operation=%q,status_class=%q} %d\n", k.operation, k.statusClass, c.requests[k])
+	}
+
+	operations := make([]string, 0, len(c.durations))
+	for op := range c.durations {
+		operations = append(operations, string(op))
+	}
+	sort.Strings(operations)
+
+	cw.printf("# HELP jusibe_request_duration_seconds Jusibe API call latencies, including retries.\n")
+	cw.printf("# TYPE jusibe_request_duration_seconds histogram\n")
+	for _, op := range operations {
+		h := c.durations[jusibe.Operation(op)]
+		for i, upperBound := range c.buckets {
+			cw.printf("jusibe_request_duration_seconds_bucket{operation=%q,le=%q} %d\n", op, formatFloat(upperBound), h.counts[i])
+		}
+		cw.printf("jusibe_request_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", op, h.count)
+		cw.printf("jusibe_request_duration_seconds_sum{operation=%q} %s\n", op, formatFloat(h.sum))
+		cw.printf("jusibe_request_duration_seconds_count{operation=%q} %d\n", op, h.count)
+	}
+
+	cw.printf("# HELP jusibe_sms_credits_used_total SMS credits used by sent messages.\n")
+	cw.printf("# TYPE jusibe_sms_credits_used_total counter\n")
+	cw.printf("jusibe_sms_credits_used_total %d\n", c.creditsUsed)
+
+	if c.hasCredits {
+		cw.printf("# HELP jusibe_sms_credits Last observed SMS credits balance.\n")
+		cw.printf("# TYPE jusibe_sms_credits gauge\n")
+		cw.printf("jusibe_sms_credits %s\n", formatFloat(c.credits))
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+// formatFloat formats v like Prometheus does
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter writes formatted strings until an error occurs, counting written bytes
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) printf(format string, args ...interface{}) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	t.Run("Collector should aggregate observations", func(t *testing.T) {
+		c := NewCollector(1, 0.1)
+		ctx := context.Background()
+
+		c.ObserveCall(ctx, jusibe.CallObservation{Operation: jusibe.OpSendSMS, StatusCode: 200, Latency: 50 * time.Millisecond, CreditsUsed: 2})
+		c.ObserveCall(ctx, jusibe.CallObservation{Operation: jusibe.OpSendSMS, StatusCode: 200, Latency: 500 * time.Millisecond, CreditsUsed: 1})
+		c.ObserveCall(ctx, jusibe.CallObservation{Operation: jusibe.OpSendSMS, Latency: 2 * time.Second, Err: errors.New("timeout")})
+		c.ObserveCall(ctx, jusibe.CallObservation{Operation: jusibe.OpSendSMS, StatusCode: 200, Latency: 450 * time.Millisecond, Err: errors.New("invalid character '<'")})
+		c.ObserveCall(ctx, jusibe.CallObservation{Operation: jusibe.OpCheckSMSCredits, StatusCode: 401, Latency: 50 * time.Millisecond})
+		c.ObserveCall(ctx, jusibe.CallObservation{Operation: jusibe.OpCheckSMSCredits, StatusCode: 200, Latency: 50 * time.Millisecond, Credits: 1250.5, HasCredits: true})
+
+		var b strings.Builder
+		n, err := c.WriteTo(&b)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(b.Len()), n)
+
+		assert.Equal(t, `# HELP jusibe_requests_total Jusibe API calls by operation and http status class.
+# TYPE jusibe_requests_total counter
+jusibe_requests_total{operation="CheckSMSCredits",status_class="2xx"} 1
+jusibe_requests_total{operation="CheckSMSCredits",status_class="4xx"} 1
+jusibe_requests_total{operation="SendSMS",status_class="2xx"} 2
+jusibe_requests_total{operation="SendSMS",status_class="error"} 2
+# HELP jusibe_request_duration_seconds Jusibe API call latencies, including retries.
+# TYPE jusibe_request_duration_seconds histogram
+jusibe_request_duration_seconds_bucket{operation="CheckSMSCredits",le="0.1"} 2
+jusibe_request_duration_seconds_bucket{operation="CheckSMSCredits",le="1"} 2
+jusibe_request_duration_seconds_bucket{operation="CheckSMSCredits",le="+Inf"} 2
+jusibe_request_duration_seconds_sum{operation="CheckSMSCredits"} 0.1
+jusibe_request_duration_seconds_count{operation="CheckSMSCredits"} 2
+jusibe_request_duration_seconds_bucket{operation="SendSMS",le="0.1"} 1
+jusibe_request_duration_seconds_bucket{operation="SendSMS",le="1"} 3
+jusibe_request_duration_seconds_bucket{operation="SendSMS",le="+Inf"} 4
+jusibe_request_duration_seconds_sum{operation="SendSMS"} 3
+jusibe_request_duration_seconds_count{operation="SendSMS"} 4
+# HELP jusibe_sms_credits_used_total SMS credits used by sent messages.
+# TYPE jusibe_sms_credits_used_total counter
+jusibe_sms_credits_used_total 3
+# HELP jusibe_sms_credits Last observed SMS credits balance.
+# TYPE jusibe_sms_credits gauge
+jusibe_sms_credits 1250.5
+`, b.String())
+	})
+
+	t.Run("StatusClass should group status codes", func(t *testing.T) {
+		assert.Equal(t, "2xx", StatusClass(204))
+		assert.Equal(t, "5xx", StatusClass(503))
+		assert.Equal(t, "error", StatusClass(0))
+	})
+
+	t.Run("Collector should instrument a client and serve metrics over http", func(t *testing.T) {
+		srv := jusibetest.NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		c := NewCollector()
+		cfg := srv.Config()
+		cfg.Metrics = c
+
+		j, err := jusibe.New(cfg)
+		assert.NoError(t, err)
+
+		_, _, err = j.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		_, _, err = j.CheckSMSCredits(context.Background())
+		assert.NoError(t, err)
+		_, _, err = j.CheckSMSDeliveryStatus(context.Background(), "unknown")
+		assert.True(t, errors.Is(err, jusibe.ErrNotFound))
+
+		metricsServer := httptest.NewServer(c)
+		defer metricsServer.Close()
+
+		res, err := http.Get(metricsServer.URL)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", res.Header.Get("Content-Type"))
+		assert.Contains(t, string(body), `jusibe_requests_total{operation="SendSMS",status_class="2xx"} 1`)
+		assert.Contains(t, string(body), `jusibe_requests_total{operation="CheckSMSDeliveryStatus",status_class="4xx"} 1`)
+		assert.Contains(t, string(body), "jusibe_sms_credits_used_total 1\n")
+		assert.Contains(t, string(body), "jusibe_sms_credits 9\n")
+	})
+}
@@ -0,0 +1,263 @@
+/*
+Package msisdn parses, validates and normalizes phone numbers before they are sent to Jusibe.
+
+Nigerian numbers are accepted in any of the common local and international forms, e.g 0803 000 0000,
+803-000-0000, 2348030000000, +234 (0) 803 000 0000 or 002348030000000. Numbers from other countries must
+be written in international form with a leading + or 00.
+
+Example Usage:
+
+	n, err := msisdn.Parse("+234 803-000-0000")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(n.E164())     // +2348030000000
+	fmt.Println(n.National()) // 08030000000
+	fmt.Println(n.Operator()) // MTN
+
+	// Normalize every recipient before it is sent
+	cfg := &jusibe.Config{
+		PublicKey:          os.Getenv("JUSIBE_PUBLIC_KEY"),
+		AccessToken:        os.Getenv("JUSIBE_ACCESS_TOKEN"),
+		NormalizeRecipient: msisdn.Normalize,
+	}
+*/
+package msisdn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NigeriaCountryCode is the Nigerian country calling code
+const NigeriaCountryCode = "234"
+
+const (
+	// E.164 numbers have at most 15 digits including the country code
+	maxE164Digits = 15
+	minE164Digits = 8
+
+	nigerianNSNLength = 10
+)
+
+// ErrInvalid is wrapped by every error returned by Parse and Normalize
+var ErrInvalid = errors.New("msisdn: invalid phone number")
+
+// Operator is a Nigerian mobile network operator
+type Operator string
+
+const (
+	// OperatorUnknown is returned for non-Nigerian numbers and unallocated prefixes
+	OperatorUnknown Operator = ""
+
+	// OperatorMTN is MTN Nigeria
+	OperatorMTN Operator = "MTN"
+
+	// OperatorAirtel is Airtel Nigeria
+	OperatorAirtel Operator = "Airtel"
+
+	// OperatorGlo is Globacom
+	OperatorGlo Operator = "Glo"
+
+	// Operator9mobile is 9mobile, formerly Etisalat Nigeria
+	Operator9mobile Operator = "9mobile"
+
+	// OperatorNtel is ntel
+	OperatorNtel Operator = "ntel"
+
+	// OperatorSmile is Smile Communications Nigeria
+	OperatorSmile Operator = "Smile"
+)
+
+// operatorPrefixes maps national prefixes (without the trunk 0) to operators
+// Four digit prefixes (five digits with the trunk 0) are checked before three digit ones
+var operatorPrefixes = map[string]Operator{
+	"7025": OperatorMTN, "7026": OperatorMTN, "703": OperatorMTN, "704": OperatorMTN, "706": OperatorMTN,
+	"803": OperatorMTN, "806": OperatorMTN, "810": OperatorMTN, "813": OperatorMTN, "814": OperatorMTN,
+	"816": OperatorMTN, "903": OperatorMTN, "906": OperatorMTN, "913": OperatorMTN, "916": OperatorMTN,
+
+	"701": OperatorAirtel, "708": OperatorAirtel, "802": OperatorAirtel, "808": OperatorAirtel, "812": OperatorAirtel,
+	"901": OperatorAirtel, "902": OperatorAirtel, "904": OperatorAirtel, "907": OperatorAirtel, "912": OperatorAirtel,
+
+	"705": OperatorGlo, "805": OperatorGlo, "807": OperatorGlo, "811": OperatorGlo, "815": OperatorGlo,
+	"905": OperatorGlo, "915": OperatorGlo,
+
+	"809": Operator9mobile, "817": Operator9mobile, "818": Operator9mobile, "908": Operator9mobile, "909": Operator9mobile,
+
+	"804": OperatorNtel,
+
+	"702": OperatorSmile,
+}
+
+// Number is a parsed phone number
+type Number struct {
+	// CountryCode is the country calling code, e.g 234
+	CountryCode string
+
+	// NationalNumber is the national significant number, i.e the digits after the country code without a trunk prefix
+	// For Nigerian numbers it is always the ten digit mobile number, e.g 8030000000
+	NationalNumber string
+}
+
+// Parse parses s into a Number
+// Spaces, dashes, dots and brackets are ignored
+func Parse(s string) (n Number, err error) {
+	digits, international, err := clean(s)
+	if err != nil {
+		return
+	}
+
+	switch {
+	case international && !strings.HasPrefix(digits, NigeriaCountryCode):
+		n, err = parseInternational(s, digits)
+	case international, strings.HasPrefix(digits, NigeriaCountryCode) && len(digits) > nigerianNSNLength:
+		n, err = parseNigerian(s, strings.TrimPrefix(digits, NigeriaCountryCode))
+	default:
+		n, err = parseNigerian(s, digits)
+	}
+
+	return
+}
+
+// Normalize parses s and returns it in international form without the leading +, e.g 2348030000000,
+// which is the form sent to Jusibe
+func Normalize(s string) (string, error) {
+	n, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return n.International(), nil
+}
+
+// E164 returns the number in E.164 form, e.g +2348030000000
+func (n Number) E164() string {
+	return "+" + n.International()
+}
+
+// International returns the number in international form without the leading +, e.g 2348030000000
+func (n Number) International() string {
+	return n.CountryCode + n.NationalNumber
+}
+
+// National returns Nigerian numbers in local form with the trunk 0, e.g 08030000000
+// Other numbers are returned in international form
+func (n Number) National() string {
+	if !n.IsNigerian() {
+		return n.International()
+	}
+	return "0" + n.NationalNumber
+}
+
+// IsNigerian reports whether the number is a Nigerian number
+func (n Number) IsNigerian() bool {
+	return n.CountryCode == NigeriaCountryCode
+}
+
+// Operator returns the mobile network operator which was allocated the number prefix
+// Numbers ported to another operator keep reporting the original operator
+// Numbers built by hand with a NationalNumber too short to hold a prefix report OperatorUnknown
+func (n Number) Operator() Operator {
+	if !n.IsNigerian() || len(n.NationalNumber) < 4 {
+		return OperatorUnknown
+	}
+
+	if op, ok := operatorPrefixes[n.NationalNumber[:4]]; ok {
+		return op
+	}
+
+	return operatorPrefixes[n.NationalNumber[:3]]
+}
+
+// String returns the number in E.164 form
+func (n Number) String() string {
+	return n.E164()
+}
+
+// clean strips formatting characters from s
+// It reports whether s was written in international form, i.e with a leading + or 00
+func clean(s string) (digits string, international bool, err error) {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "+") {
+		international, trimmed = true, trimmed[1:]
+	}
+
+	var b strings.Builder
+	for _, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, invalid(s, fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	digits = b.String()
+
+	if !international && strings.HasPrefix(digits, "00") {
+		international, digits = true, digits[2:]
+	}
+
+	if digits == "" {
+		err = invalid(s, "no digits")
+	}
+
+	return
+}
+
+// parseNigerian validates a Nigerian number given its digits without the country code
+func parseNigerian(input, digits string) (n Number, err error) {
+	// Strip the trunk prefix, which is also commonly written after the country code, e.g +234 (0) 803...
+	if len(digits) == nigerianNSNLength+1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+
+	if len(digits) != nigerianNSNLength {
+		return n, invalid(input, fmt.Sprintf("Nigerian mobile numbers have %d digits after the country code", nigerianNSNLength))
+	}
+
+	if (digits[0] != '7' && digits[0] != '8' && digits[0] != '9') || (digits[1] != '0' && digits[1] != '1') {
+		return n, invalid(input, "not a Nigerian mobile number")
+	}
+
+	return Number{CountryCode: NigeriaCountryCode, NationalNumber: digits}, nil
+}
+
+// parseInternational validates a non-Nigerian number given its digits in international form
+// Country codes have one to three digits, and only E.164 length limits are checked
+func parseInternational(input, digits string) (n Number, err error) {
+	if len(digits) < minE164Digits || len(digits) > maxE164Digits {
+		return n, invalid(input, fmt.Sprintf("international numbers have between %d and %d digits", minE164Digits, maxE164Digits))
+	}
+
+	if digits[0] == '0' {
+		return n, invalid(input, "country codes don't start with 0")
+	}
+
+	ccLength := countryCodeLength(digits)
+
+	return Number{CountryCode: digits[:ccLength], NationalNumber: digits[ccLength:]}, nil
+}
+
+// countryCodeLength returns the length of the country calling code digits starts with
+// It relies on the ITU zone structure: zones 1 and 7 use single digit codes, and a handful of
+// two digit codes are listed explicitly. Everything else is assumed to be a three digit code
+func countryCodeLength(digits string) int {
+	if digits[0] == '1' || digits[0] == '7' {
+		return 1
+	}
+
+	switch digits[:2] {
+	case "20", "27", "30", "31", "32", "33", "34", "36", "39", "40", "41", "43", "44", "45", "46", "47", "48", "49",
+		"51", "52", "53", "54", "55", "56", "57", "58", "60", "61", "62", "63", "64", "65", "66",
+		"81", "82", "84", "86", "90", "91", "92", "93", "94", "95", "98":
+		return 2
+	}
+
+	return 3
+}
+
+func invalid(input, reason string) error {
+	return fmt.Errorf("%w: %q %s", ErrInvalid, input, reason)
+}
@@ -0,0 +1,101 @@
+package msisdn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Parse should accept common Nigerian formats", func(t *testing.T) {
+		for _, input := range []string{
+			"08030000000",
+			"0803 000 0000",
+			"803-000-0000",
+			"2348030000000",
+			"+2348030000000",
+			"+234 803-000-0000",
+			"+234 (0) 803 000 0000",
+			"002348030000000",
+			" (0803) 000.0000 ",
+		} {
+			n, err := Parse(input)
+			assert.NoError(t, err, input)
+			assert.Equal(t, "+2348030000000", n.E164(), input)
+			assert.Equal(t, "2348030000000", n.International(), input)
+			assert.Equal(t, "08030000000", n.National(), input)
+			assert.True(t, n.IsNigerian(), input)
+		}
+	})
+
+	t.Run("Parse should accept international numbers", func(t *testing.T) {
+		n, err := Parse("+44 7911 123456")
+		assert.NoError(t, err)
+		assert.Equal(t, Number{CountryCode: "44", NationalNumber: "7911123456"}, n)
+		assert.Equal(t, "+447911123456", n.String())
+		assert.Equal(t, "447911123456", n.National())
+		assert.False(t, n.IsNigerian())
+		assert.Equal(t, OperatorUnknown, n.Operator())
+
+		n, err = Parse("001 415 555 2671")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", n.CountryCode)
+
+		n, err = Parse("+233 24 123 4567")
+		assert.NoError(t, err)
+		assert.Equal(t, "233", n.CountryCode)
+	})
+
+	t.Run("Parse should reject malformed numbers", func(t *testing.T) {
+		for _, input := range []string{
+			"",
+			"+",
+			"0803000000",
+			"080300000000",
+			"08030000000x",
+			"06030000000",
+			"08230000000",
+			"+2346030000000",
+			"+44 123",
+			"+0 123 456 789",
+			"+1234567890123456",
+		} {
+			_, err := Parse(input)
+			assert.True(t, errors.Is(err, ErrInvalid), "%q should be invalid", input)
+		}
+	})
+
+	t.Run("Operator should identify Nigerian operators from the prefix", func(t *testing.T) {
+		cases := map[string]Operator{
+			"08030000000": OperatorMTN,
+			"07025000000": OperatorMTN,
+			"07020000000": OperatorSmile,
+			"08020000000": OperatorAirtel,
+			"09050000000": OperatorGlo,
+			"08090000000": Operator9mobile,
+			"08040000000": OperatorNtel,
+			"07090000000": OperatorUnknown,
+		}
+
+		for input, op := range cases {
+			n, err := Parse(input)
+			assert.NoError(t, err, input)
+			assert.Equal(t, op, n.Operator(), input)
+		}
+
+		for _, nationalNumber := range []string{"", "8", "803"} {
+			n := Number{CountryCode: NigeriaCountryCode, NationalNumber: nationalNumber}
+			assert.Equal(t, OperatorUnknown, n.Operator(), "should not panic on %q", nationalNumber)
+		}
+	})
+
+	t.Run("Normalize should return the Jusibe form", func(t *testing.T) {
+		to, err := Normalize("0803 000 0000")
+		assert.NoError(t, err)
+		assert.Equal(t, "2348030000000", to)
+
+		_, err = Normalize("12345")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,383 @@
+/*
+Package otp sends one-time passcodes with Jusibe and verifies them.
+
+Codes are generated with crypto/rand and only a salted SHA-256 hash of each code is stored, with a TTL,
+in a pluggable Store. Verification is limited to a number of attempts per code, and a new code can only
+be sent to the same phone number once the resend cooldown has elapsed.
+
+Example Usage:
+
+	s, err := otp.New(j, &otp.Config{
+		From:           "MyShop",
+		Template:       "Your MyShop code is {{.Code}}. It expires in {{.Minutes}} minutes",
+		NormalizePhone: msisdn.Normalize,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := s.Send(ctx, "08030000000"); err != nil {
+		var cooldownErr *otp.CooldownError
+		if errors.As(err, &cooldownErr) {
+			fmt.Println("retry in", cooldownErr.RetryAfter)
+		}
+	}
+
+	if err := s.Verify(ctx, "08030000000", code); err != nil {
+		// otp.ErrInvalidCode, otp.ErrExpired, otp.ErrTooManyAttempts or otp.ErrNotFound
+	}
+*/
+package otp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+)
+
+const (
+	defaultTemplate       = "Your verification code is {{.Code}}. It expires in {{.Minutes}} minutes"
+	defaultLength         = 6
+	defaultTTL            = (time.Minute * 5)
+	defaultMaxAttempts    = 5
+	defaultResendCooldown = (time.Second * 60)
+
+	minLength = 4
+	maxLength = 10
+	saltSize  = 16
+)
+
+var (
+	// ErrNotFound is returned by Verify when no code is pending for the phone number
+	ErrNotFound = errors.New("otp: no pending code")
+
+	// ErrInvalidCode is returned by Verify when the code does not match
+	ErrInvalidCode = errors.New("otp: invalid code")
+
+	// ErrExpired is returned by Verify when the code outlived Config.TTL
+	ErrExpired = errors.New("otp: code expired")
+
+	// ErrTooManyAttempts is returned by Verify once Config.MaxAttempts verifications failed. The code is then discarded
+	ErrTooManyAttempts = errors.New("otp: too many attempts")
+
+	// ErrCooldown is matched by a *CooldownError
+	ErrCooldown = errors.New("otp: resend cooldown")
+)
+
+// CooldownError is returned by Send when a code was sent to the phone number less than Config.ResendCooldown ago
+// It matches ErrCooldown with errors.Is
+type CooldownError struct {
+	// RetryAfter is the time left before a new code can be sent
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrCooldown, e.RetryAfter)
+}
+
+// Is reports whether target is ErrCooldown
+func (e *CooldownError) Is(target error) bool {
+	return target == ErrCooldown
+}
+
+// Config is OTP Service configuration
+// From is required, every other field is optional
+type Config struct {
+	// From is the SenderID codes are sent from
+	From string
+
+	// Template is the text/template of the message. It is rendered with .Code, .TTL and .Minutes
+	// Defaults to "Your verification code is {{.Code}}. It expires in {{.Minutes}} minutes"
+	Template string
+
+	// Length is the number of digits of codes, between 4 and 10. Defaults to 6
+	Length int
+
+	// TTL is how long codes are accepted for. Defaults to 5m
+	TTL time.Duration
+
+	// MaxAttempts is the number of failed verifications after which a code is discarded. Defaults to 5
+	MaxAttempts int
+
+	// ResendCooldown is the minimum wait between two codes sent to the same phone number. Defaults to 60s
+	ResendCooldown time.Duration
+
+	// Store holds pending codes. Defaults to a MemoryStore
+	Store Store
+
+	// NormalizePhone, when set, is applied to phone numbers before codes are sent, stored and verified,
+	// so that different forms of the same number share a code. Use msisdn.Normalize to validate phone numbers
+	NormalizePhone func(phone string) (string, error)
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Service sends and verifies one-time passcodes
+// Create a Service with New. Send and Verify calls for the same phone number are serialized, so that concurrent
+// guesses can't exceed Config.MaxAttempts. Services sharing a Store across processes are not serialized with each other
+type Service struct {
+	client jusibe.Client
+	cfg    Config
+	tmpl   *template.Template
+	locks  keyLocks
+}
+
+// keyLocks is a set of mutexes keyed by Store key, which only holds the keys currently locked
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks key and returns the function unlocking it
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*keyLock{}
+	}
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.mu.Lock()
+
+	return func() {
+		kl.mu.Unlock()
+
+		l.mu.Lock()
+		if kl.refs--; kl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// templateData is the data the message template is rendered with
+type templateData struct {
+	Code    string
+	TTL     time.Duration
+	Minutes int
+}
+
+// New creates a Service sending codes with c
+func New(c jusibe.Client, cfg *Config) (s *Service, err error) {
+	if c == nil {
+		return nil, errors.New("otp: a jusibe.Client is required")
+	}
+
+	var sc Config
+	if cfg != nil {
+		sc = *cfg
+	}
+
+	if err = jusibe.ValidateSenderID(sc.From); err != nil {
+		return
+	}
+
+	if sc.Template == "" {
+		sc.Template = defaultTemplate
+	}
+	if sc.Length == 0 {
+		sc.Length = defaultLength
+	}
+	if sc.TTL <= 0 {
+		sc.TTL = defaultTTL
+	}
+	if sc.MaxAttempts <= 0 {
+		sc.MaxAttempts = defaultMaxAttempts
+	}
+	if sc.ResendCooldown <= 0 {
+		sc.ResendCooldown = defaultResendCooldown
+	}
+	if sc.Store == nil {
+		sc.Store = NewMemoryStore()
+	}
+	if sc.Now == nil {
+		sc.Now = time.Now
+	}
+
+	if sc.Length < minLength || sc.Length > maxLength {
+		return nil, fmt.Errorf("otp: Length must be between %d and %d, got %d", minLength, maxLength, sc.Length)
+	}
+
+	tmpl, err := template.New("otp").Option("missingkey=error").Parse(sc.Template)
+	if err != nil {
+		return
+	}
+
+	s = &Service{client: c, cfg: sc, tmpl: tmpl}
+
+	return
+}
+
+// Send generates a new code, stores its hash and sends it to phone, replacing any pending code
+// It returns a *CooldownError when a code was sent to phone less than Config.ResendCooldown ago
+func (s *Service) Send(ctx context.Context, phone string) (ssr *jusibe.SMSResponse, err error) {
+	key, err := s.key(phone)
+	if err != nil {
+		return
+	}
+
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	now := s.cfg.Now()
+
+	existing, err := s.cfg.Store.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	if existing != nil {
+		if wait := existing.SentAt.Add(s.cfg.ResendCooldown).Sub(now); wait > 0 {
+			return nil, &CooldownError{RetryAfter: wait}
+		}
+	}
+
+	code, err := generateCode(s.cfg.Length)
+	if err != nil {
+		return
+	}
+
+	var body strings.Builder
+	data := templateData{Code: code, TTL: s.cfg.TTL, Minutes: int(s.cfg.TTL.Round(time.Minute) / time.Minute)}
+	if err = s.tmpl.Execute(&body, data); err != nil {
+		return
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return
+	}
+
+	entry := &Entry{Hash: hash(salt, code), Salt: salt, SentAt: now, ExpiresAt: now.Add(s.cfg.TTL)}
+	if err = s.save(ctx, key, entry, s.retainUntil(entry), now); err != nil {
+		return
+	}
+
+	if ssr, _, err = s.client.SendSMS(ctx, key, s.cfg.From, body.String()); err != nil {
+		// A code which was never delivered must neither be accepted nor block a retry
+		_ = s.cfg.Store.Delete(ctx, key)
+		ssr = nil
+	}
+
+	return
+}
+
+// Verify checks code against the code pending for phone
+// The code is discarded once it is verified, or once Config.MaxAttempts verifications failed
+func (s *Service) Verify(ctx context.Context, phone, code string) (err error) {
+	key, err := s.key(phone)
+	if err != nil {
+		return
+	}
+
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	entry, err := s.cfg.Store.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	if entry == nil {
+		return ErrNotFound
+	}
+
+	now := s.cfg.Now()
+	switch {
+	case !now.Before(entry.ExpiresAt):
+		return ErrExpired
+	case entry.Attempts >= s.cfg.MaxAttempts:
+		return ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare(hash(entry.Salt, strings.TrimSpace(code)), entry.Hash) == 1 {
+		return s.cfg.Store.Delete(ctx, key)
+	}
+
+	entry.Attempts++
+	if entry.Attempts >= s.cfg.MaxAttempts {
+		// The entry is kept until the cooldown elapses, so that the phone number can't immediately request a new code
+		if err = s.save(ctx, key, entry, entry.SentAt.Add(s.cfg.ResendCooldown), now); err != nil {
+			return
+		}
+		return ErrTooManyAttempts
+	}
+
+	if err = s.save(ctx, key, entry, s.retainUntil(entry), now); err != nil {
+		return
+	}
+
+	return ErrInvalidCode
+}
+
+// retainUntil returns when entry can be dropped from the Store
+// The entry outlives the code when the cooldown is longer, so that the cooldown is still enforced
+func (s *Service) retainUntil(entry *Entry) time.Time {
+	if cooldownEnd := entry.SentAt.Add(s.cfg.ResendCooldown); cooldownEnd.After(entry.ExpiresAt) {
+		return cooldownEnd
+	}
+	return entry.ExpiresAt
+}
+
+// save stores entry until the specified time, or deletes it when that time has passed
+func (s *Service) save(ctx context.Context, key string, entry *Entry, until, now time.Time) error {
+	if !until.After(now) {
+		return s.cfg.Store.Delete(ctx, key)
+	}
+	return s.cfg.Store.Set(ctx, key, entry, until.Sub(now))
+}
+
+// key returns the Store key of phone
+func (s *Service) key(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	if s.cfg.NormalizePhone == nil {
+		if phone == "" {
+			return "", &jusibe.RecipientError{Recipient: phone, Err: errors.New("phone number is required")}
+		}
+		return phone, nil
+	}
+
+	normalized, err := s.cfg.NormalizePhone(phone)
+	if err != nil {
+		return "", &jusibe.RecipientError{Recipient: phone, Err: err}
+	}
+
+	return normalized, nil
+}
+
+// generateCode returns a uniformly random code of length digits
+func generateCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+func hash(salt []byte, code string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(code))
+	return h.Sum(nil)
+}
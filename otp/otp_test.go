@@ -0,0 +1,264 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibefake"
+	"github.com/azeezolaniran2016/jusibe-go/msisdn"
+	"github.com/stretchr/testify/assert"
+)
+
+var codePattern = regexp.MustCompile(`\d{4,}`)
+
+// lastCode extracts the code from the last SMS sent by fake
+func lastCode(t *testing.T, fake *jusibefake.Fake) string {
+	sent := fake.Sent()
+	if !assert.NotEmpty(t, sent) {
+		return ""
+	}
+	return codePattern.FindString(sent[len(sent)-1].Body)
+}
+
+// slowStore delays Get after reading, so that concurrent calls overlap between reading and writing an entry
+type slowStore struct {
+	*MemoryStore
+}
+
+func (s slowStore) Get(ctx context.Context, key string) (*Entry, error) {
+	defer time.Sleep(time.Millisecond)
+	return s.MemoryStore.Get(ctx, key)
+}
+
+func TestOTP(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("New should validate the config", func(t *testing.T) {
+		_, err := New(nil, &Config{From: "MyShop"})
+		assert.Error(t, err)
+
+		_, err = New(jusibefake.New(10), &Config{From: ""})
+		assert.True(t, errors.Is(err, jusibe.ErrInvalidSender))
+
+		_, err = New(jusibefake.New(10), &Config{From: "MyShop", Length: 3})
+		assert.EqualError(t, err, "otp: Length must be between 4 and 10, got 3")
+
+		_, err = New(jusibefake.New(10), &Config{From: "MyShop", Template: "{{.Code"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Send should render the template and Verify should accept the code once", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		store := NewMemoryStore()
+		s, err := New(fake, &Config{From: "MyShop", Template: "Code: {{.Code}} ({{.Minutes}}m)", Length: 8, TTL: 10 * time.Minute, Store: store})
+		assert.NoError(t, err)
+
+		ssr, err := s.Send(ctx, "08030000000")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ssr.MessageID)
+
+		sent := fake.Sent()
+		assert.Len(t, sent, 1)
+		assert.Regexp(t, `^Code: \d{8} \(10m\)$`, sent[0].Body)
+		assert.Equal(t, "MyShop", sent[0].From)
+
+		code := lastCode(t, fake)
+		entry, err := store.Get(ctx, "08030000000")
+		assert.NoError(t, err)
+		assert.Len(t, entry.Hash, 32, "only a SHA-256 hash of the code should be stored")
+
+		assert.NoError(t, s.Verify(ctx, "08030000000", code))
+		assert.Equal(t, ErrNotFound, s.Verify(ctx, "08030000000", code), "codes should only be accepted once")
+	})
+
+	t.Run("Verify should enforce attempt limits", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		s, err := New(fake, &Config{From: "MyShop", MaxAttempts: 2})
+		assert.NoError(t, err)
+
+		_, err = s.Send(ctx, "08030000000")
+		assert.NoError(t, err)
+		code := lastCode(t, fake)
+
+		assert.Equal(t, ErrInvalidCode, s.Verify(ctx, "08030000000", "0000"))
+		assert.Equal(t, ErrTooManyAttempts, s.Verify(ctx, "08030000000", "0000"))
+		assert.Equal(t, ErrTooManyAttempts, s.Verify(ctx, "08030000000", code), "the code should be discarded")
+	})
+
+	t.Run("Verify should enforce attempt limits for concurrent guesses", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		store := NewMemoryStore()
+		s, err := New(fake, &Config{From: "MyShop", Length: 4, MaxAttempts: 3, Store: slowStore{store}})
+		assert.NoError(t, err)
+
+		_, err = s.Send(ctx, "08030000000")
+		assert.NoError(t, err)
+		code := lastCode(t, fake)
+
+		wrong := "0000"
+		if code == wrong {
+			wrong = "1111"
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		results := map[error]int{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := s.Verify(ctx, "08030000000", wrong)
+				mu.Lock()
+				results[err]++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 2, results[ErrInvalidCode], "only MaxAttempts guesses should be evaluated")
+		assert.Equal(t, 18, results[ErrTooManyAttempts])
+
+		entry, err := store.Get(ctx, "08030000000")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, entry.Attempts)
+		assert.Equal(t, ErrTooManyAttempts, s.Verify(ctx, "08030000000", code))
+		assert.Empty(t, s.locks.locks, "unused locks should be released")
+	})
+
+	t.Run("Send should enforce the resend cooldown for concurrent sends", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		s, err := New(fake, &Config{From: "MyShop", Store: slowStore{NewMemoryStore()}})
+		assert.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = s.Send(ctx, "08030000000")
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, fake.Sent(), 1)
+	})
+
+	t.Run("Verify should reject expired codes", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		now := time.Now()
+		s, err := New(fake, &Config{From: "MyShop", TTL: time.Minute, Now: func() time.Time { return now }})
+		assert.NoError(t, err)
+
+		_, err = s.Send(ctx, "08030000000")
+		assert.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, ErrExpired, s.Verify(ctx, "08030000000", lastCode(t, fake)))
+	})
+
+	t.Run("Send should enforce the resend cooldown per normalized number", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		now := time.Now()
+		s, err := New(fake, &Config{
+			From:           "MyShop",
+			ResendCooldown: 30 * time.Second,
+			NormalizePhone: msisdn.Normalize,
+			Now:            func() time.Time { return now },
+		})
+		assert.NoError(t, err)
+
+		_, err = s.Send(ctx, "08030000000")
+		assert.NoError(t, err)
+		assert.Equal(t, "2348030000000", fake.Sent()[0].To)
+		first := lastCode(t, fake)
+
+		now = now.Add(10 * time.Second)
+		_, err = s.Send(ctx, "+234 803 000 0000")
+		assert.True(t, errors.Is(err, ErrCooldown))
+		var cooldownErr *CooldownError
+		if assert.True(t, errors.As(err, &cooldownErr)) {
+			assert.Equal(t, 20*time.Second, cooldownErr.RetryAfter)
+		}
+
+		_, err = s.Send(ctx, "12345")
+		assert.True(t, errors.Is(err, jusibe.ErrInvalidRecipient))
+		assert.True(t, errors.Is(err, msisdn.ErrInvalid))
+
+		now = now.Add(20 * time.Second)
+		_, err = s.Send(ctx, "08030000000")
+		assert.NoError(t, err)
+		second := lastCode(t, fake)
+
+		if first != second {
+			assert.Equal(t, ErrInvalidCode, s.Verify(ctx, "08030000000", first), "a new code should replace the pending one")
+		}
+		assert.NoError(t, s.Verify(ctx, "2348030000000", second))
+	})
+
+	t.Run("Send should not keep codes which failed to send", func(t *testing.T) {
+		fake := jusibefake.New(10)
+		fake.Fail(jusibefake.OpSendSMS, &jusibe.APIError{StatusCode: http.StatusServiceUnavailable})
+		s, err := New(fake, &Config{From: "MyShop"})
+		assert.NoError(t, err)
+
+		ssr, err := s.Send(ctx, "08030000000")
+		assert.Nil(t, ssr)
+		assert.True(t, errors.Is(err, jusibe.ErrServer))
+
+		_, err = s.Send(ctx, "08030000000")
+		assert.NoError(t, err, "a failed send should not trigger the cooldown")
+	})
+
+	t.Run("generateCode should pad codes to length", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			code, err := generateCode(4)
+			assert.NoError(t, err)
+			assert.Regexp(t, `^\d{4}$`, code)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	assert.NoError(t, s.Set(ctx, "a", &Entry{Attempts: 1}, time.Minute))
+	assert.NoError(t, s.Set(ctx, "b", &Entry{Attempts: 2}, time.Hour))
+
+	e, err := s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.Attempts)
+
+	e.Attempts = 5
+	e, _ = s.Get(ctx, "a")
+	assert.Equal(t, 1, e.Attempts, "Get should return a copy")
+
+	now = now.Add(time.Minute)
+	e, err = s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Nil(t, e, "expired entries should not be returned")
+
+	assert.NoError(t, s.Set(ctx, "c", &Entry{}, time.Minute))
+	assert.Equal(t, 2, s.Len())
+
+	assert.NoError(t, s.Delete(ctx, "b"))
+	e, _ = s.Get(ctx, "b")
+	assert.Nil(t, e)
+
+	assert.NoError(t, s.Set(ctx, "d", &Entry{}, time.Second))
+	now = now.Add(2 * time.Second)
+	assert.NoError(t, s.Set(ctx, "e", &Entry{}, time.Minute))
+	assert.Equal(t, 3, s.Len(), "expired entries should be removed at most once per interval")
+
+	now = now.Add(memoryStorePruneInterval)
+	assert.NoError(t, s.Set(ctx, "e", &Entry{}, time.Minute))
+	assert.Equal(t, 1, s.Len(), "expired entries should be removed once the interval elapsed")
+}
@@ -0,0 +1,119 @@
+package otp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry is a pending code stored for a phone number
+// The code itself is never stored, only a salted hash of it
+type Entry struct {
+	Hash []byte
+	Salt []byte
+
+	// SentAt is when the code was sent, used to enforce the resend cooldown
+	SentAt time.Time
+
+	// ExpiresAt is when the code stops being accepted
+	ExpiresAt time.Time
+
+	// Attempts is the number of failed verifications
+	Attempts int
+}
+
+// Store persists pending codes, keyed by phone number
+// Implementations must be safe for concurrent use. Entries may be dropped once their ttl elapses
+type Store interface {
+	// Get returns the entry stored for key, or nil when there is none
+	Get(ctx context.Context, key string) (*Entry, error)
+
+	// Set stores e for key, replacing any previous entry, for at least ttl
+	Set(ctx context.Context, key string, e *Entry, ttl time.Duration) error
+
+	// Delete removes the entry stored for key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// memoryStorePruneInterval is how often MemoryStore.Set removes expired entries
+const memoryStorePruneInterval = time.Minute
+
+type memoryEntry struct {
+	entry   Entry
+	expires time.Time
+}
+
+// MemoryStore is an in-memory Store, suitable for a single process
+// Create a MemoryStore with NewMemoryStore
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastPrune time.Time
+
+	// now returns the current time. Defaults to time.Now
+	now func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}, now: time.Now}
+}
+
+// Get returns a copy of the entry stored for key, or nil when there is none or it expired
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+
+	if !s.now().Before(e.expires) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+
+	entry := e.entry
+	return &entry, nil
+}
+
+// Set stores a copy of e for key for ttl
+// Expired entries of other keys are removed at most once per minute, so the store does not grow unbounded
+// while Set stays cheap
+func (s *MemoryStore) Set(ctx context.Context, key string, e *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastPrune) >= memoryStorePruneInterval {
+		for k, existing := range s.entries {
+			if !now.Before(existing.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	s.entries[key] = memoryEntry{entry: *e, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes the entry stored for key, if any
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Len returns the number of stored entries, including expired entries which were not removed yet
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
@@ -0,0 +1,535 @@
+/*
+Package pool spreads sends over several Jusibe accounts, with failover.
+
+A Pool wraps one *jusibe.Jusibe per account and implements jusibe.Client, so it can be used wherever a single
+client is, e.g with mailmerge or otp. Every send picks an ordered list of candidate accounts with a Strategy:
+
+	RoundRobin        rotates over the accounts
+	HighestCredits    prefers the account with the most SMS credits left, checking balances at most every CreditsMaxAge
+	SenderAffinity    prefers accounts whose Config.SenderIDs registry holds the SenderID, rotating within each group
+
+Accounts whose Config.SenderIDs registry refuses the SenderID are never candidates. When a send fails with
+jusibe.ErrUnauthorized, jusibe.ErrInsufficientCredits or jusibe.ErrCircuitOpen, it is retried on the next
+candidate. The Pool remembers the account owning every message id it returns, so CheckSMSDeliveryStatus and
+CheckBulkSMSStatus are sent to the right account.
+
+Example Usage:
+
+	p, err := pool.New([]pool.Account{
+		{Name: "marketing", Config: &jusibe.Config{PublicKey: "...", AccessToken: "...", SenderIDs: marketingIDs}},
+		{Name: "alerts", Config: &jusibe.Config{PublicKey: "...", AccessToken: "..."}},
+	}, &pool.Options{Strategy: pool.SenderAffinity})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ssr, _, err := p.SendSMS(ctx, "08031234567", "MyShop", "Hello World!")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	account, _ := p.Owner(ssr.MessageID)
+	sdr, _, err := p.CheckSMSDeliveryStatus(ctx, ssr.MessageID)
+*/
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/segment"
+)
+
+const (
+	defaultCreditsMaxAge = time.Minute
+	defaultMaxMessageIDs = 100000
+)
+
+// ErrNoAccount is returned when no account of the pool can send from the requested SenderID
+var ErrNoAccount = errors.New("pool: no account can send from this sender id")
+
+// Strategy orders the candidate accounts of a send
+type Strategy int
+
+const (
+	// RoundRobin rotates over the accounts
+	RoundRobin Strategy = iota
+
+	// HighestCredits prefers the account with the most SMS credits left
+	HighestCredits
+
+	// SenderAffinity prefers accounts whose Config.SenderIDs registry holds the SenderID
+	SenderAffinity
+)
+
+// Account is a Jusibe account of a Pool
+type Account struct {
+	// Name identifies the account, e.g in Owner. It is required and must be unique within the pool
+	Name string
+
+	Config *jusibe.Config
+
+	// HTTPClient, when set, is passed to jusibe.NewWithHTTPClient. jusibe.New is used when nil
+	HTTPClient *http.Client
+}
+
+// Options configures a Pool
+type Options struct {
+	Strategy Strategy
+
+	// CreditsMaxAge is how long HighestCredits trusts a balance before checking it again. Defaults to 1m
+	// Balances are lowered by the credits used by every send in between, estimated with the segment package for bulk sends
+	CreditsMaxAge time.Duration
+
+	// MaxMessageIDs is the number of message ids whose account is remembered. The oldest are forgotten first
+	// Defaults to 100000
+	MaxMessageIDs int
+
+	// Failover reports whether a failed send is retried on the next candidate account
+	// Defaults to jusibe.ErrUnauthorized, jusibe.ErrInsufficientCredits and jusibe.ErrCircuitOpen
+	Failover func(err error) bool
+}
+
+// shouldFailover is the default Options.Failover
+func shouldFailover(err error) bool {
+	return errors.Is(err, jusibe.ErrUnauthorized) || errors.Is(err, jusibe.ErrInsufficientCredits) || errors.Is(err, jusibe.ErrCircuitOpen)
+}
+
+// account is an Account with its client and cached balance
+type account struct {
+	name      string
+	client    *jusibe.Jusibe
+	senderIDs *jusibe.SenderIDRegistry
+
+	// credits, creditsAt, hasCredits and refreshing are guarded by Pool.mu
+	credits    float64
+	creditsAt  time.Time
+	hasCredits bool
+
+	// refreshing reports whether a send is checking the balance, so that other sends use the cached one
+	refreshing bool
+}
+
+// Pool is a jusibe.Client spreading calls over several accounts
+// Create a Pool with New. It is safe for concurrent use
+type Pool struct {
+	accounts      []*account
+	byName        map[string]*account
+	strategy      Strategy
+	creditsMaxAge time.Duration
+	maxMessageIDs int
+	failover      func(err error) bool
+
+	mu         sync.Mutex
+	next       int
+	owners     map[string]*account
+	remembered []string
+
+	now func() time.Time
+}
+
+var _ jusibe.Client = (*Pool)(nil)
+
+// New creates a Pool of accounts, creating a *jusibe.Jusibe per account
+func New(accounts []Account, opts *Options) (p *Pool, err error) {
+	if len(accounts) == 0 {
+		return nil, errors.New("pool: at least one account is required")
+	}
+
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Strategy < RoundRobin || o.Strategy > SenderAffinity {
+		return nil, fmt.Errorf("pool: unknown strategy %d", o.Strategy)
+	}
+	if o.CreditsMaxAge <= 0 {
+		o.CreditsMaxAge = defaultCreditsMaxAge
+	}
+	if o.MaxMessageIDs <= 0 {
+		o.MaxMessageIDs = defaultMaxMessageIDs
+	}
+	if o.Failover == nil {
+		o.Failover = shouldFailover
+	}
+
+	p = &Pool{
+		byName:        map[string]*account{},
+		strategy:      o.Strategy,
+		creditsMaxAge: o.CreditsMaxAge,
+		maxMessageIDs: o.MaxMessageIDs,
+		failover:      o.Failover,
+		owners:        map[string]*account{},
+		now:           time.Now,
+	}
+
+	for _, a := range accounts {
+		if a.Name == "" {
+			return nil, errors.New("pool: every account requires a Name")
+		}
+		if _, ok := p.byName[a.Name]; ok {
+			return nil, fmt.Errorf("pool: duplicate account %q", a.Name)
+		}
+		if a.Config == nil {
+			return nil, fmt.Errorf("pool: account %q requires a Config", a.Name)
+		}
+
+		var client *jusibe.Jusibe
+		if a.HTTPClient != nil {
+			client, err = jusibe.NewWithHTTPClient(a.Config, a.HTTPClient)
+		} else {
+			client, err = jusibe.New(a.Config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pool: account %q: %w", a.Name, err)
+		}
+
+		acc := &account{name: a.Name, client: client, senderIDs: a.Config.SenderIDs}
+		p.accounts = append(p.accounts, acc)
+		p.byName[a.Name] = acc
+	}
+
+	return
+}
+
+// Client returns the client of the named account
+func (p *Pool) Client(name string) (client *jusibe.Jusibe, ok bool) {
+	acc, ok := p.byName[name]
+	if ok {
+		client = acc.client
+	}
+	return
+}
+
+// Owner returns the name of the account which sent messageID, if the pool remembers it
+func (p *Pool) Owner(messageID string) (name string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	acc, ok := p.owners[messageID]
+	if ok {
+		name = acc.name
+	}
+	return
+}
+
+// Remember records that the named account owns messageID, e.g to restore ownership persisted before a restart
+func (p *Pool) Remember(messageID, name string) error {
+	acc, ok := p.byName[name]
+	if !ok {
+		return fmt.Errorf("pool: unknown account %q", name)
+	}
+
+	p.remember(messageID, acc)
+
+	return nil
+}
+
+// remember records that acc owns messageID, forgetting the oldest message ids beyond maxMessageIDs
+func (p *Pool) remember(messageID string, acc *account) {
+	if messageID == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.owners[messageID]; !ok {
+		p.remembered = append(p.remembered, messageID)
+	}
+	p.owners[messageID] = acc
+
+	for len(p.remembered) > p.maxMessageIDs {
+		delete(p.owners, p.remembered[0])
+		p.remembered = p.remembered[1:]
+	}
+}
+
+// owner returns the account owning messageID, or nil
+func (p *Pool) owner(messageID string) *account {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.owners[messageID]
+}
+
+// SendSMS implements jusibe.Client
+// It is a shorthand for Send with a SendSMSRequest
+func (p *Pool) SendSMS(ctx context.Context, to, from, message string) (*jusibe.SMSResponse, *http.Response, error) {
+	return p.Send(ctx, &jusibe.SendSMSRequest{To: to, From: from, Message: message})
+}
+
+// SendBulkSMS implements jusibe.Client
+// Like SendBulk, it fails over to the next candidate account on errors selected by Options.Failover
+func (p *Pool) SendBulkSMS(ctx context.Context, to, from, message string) (bsr *jusibe.BulkSMSResponse, res *http.Response, err error) {
+	err = p.each(ctx, from, func(acc *account) (sendErr error) {
+		bsr, res, sendErr = acc.client.SendBulkSMS(ctx, to, from, message)
+		if sendErr == nil {
+			p.sentBulk(acc, bsr, len(jusibe.SplitRecipients(to)), message)
+		}
+		return
+	})
+	return
+}
+
+// Send sends r with the first candidate account which doesn't fail with an error selected by Options.Failover
+func (p *Pool) Send(ctx context.Context, r *jusibe.SendSMSRequest) (ssr *jusibe.SMSResponse, res *http.Response, err error) {
+	err = p.each(ctx, r.From, func(acc *account) (sendErr error) {
+		ssr, res, sendErr = acc.client.Send(ctx, r)
+		if sendErr == nil {
+			p.remember(ssr.MessageID, acc)
+			p.spend(acc, ssr.SMSCreditsUsed)
+		}
+		return
+	})
+	return
+}
+
+// SendBulk sends r with the first candidate account which doesn't fail with an error selected by Options.Failover
+func (p *Pool) SendBulk(ctx context.Context, r *jusibe.BulkSMSRequest) (bsr *jusibe.BulkSMSResponse, res *http.Response, err error) {
+	err = p.each(ctx, r.From, func(acc *account) (sendErr error) {
+		bsr, res, sendErr = acc.client.SendBulk(ctx, r)
+		if sendErr == nil {
+			p.sentBulk(acc, bsr, len(r.To), r.Message)
+		}
+		return
+	})
+	return
+}
+
+// sentBulk records a successful bulk send of message to recipients with acc
+func (p *Pool) sentBulk(acc *account, bsr *jusibe.BulkSMSResponse, recipients int, message string) {
+	p.remember(bsr.MessageID, acc)
+	// Bulk responses don't report the credits used, so they are estimated until the balance is checked again
+	p.spend(acc, segment.Calculate(message).Credits(recipients))
+}
+
+// each calls send with every candidate account for the SenderID from, until send succeeds or fails
+// with an error which isn't selected by Options.Failover
+func (p *Pool) each(ctx context.Context, from string, send func(acc *account) error) (err error) {
+	candidates := p.candidates(ctx, from)
+	if len(candidates) == 0 {
+		return ErrNoAccount
+	}
+
+	for _, acc := range candidates {
+		err = send(acc)
+		if err == nil || !p.failover(err) {
+			return
+		}
+
+		if errors.Is(err, jusibe.ErrInsufficientCredits) {
+			p.setCredits(acc, 0)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// candidates returns the accounts which may send from the SenderID from, ordered by the pool Strategy
+func (p *Pool) candidates(ctx context.Context, from string) []*account {
+	p.mu.Lock()
+	start := p.next
+	p.next = (p.next + 1) % len(p.accounts)
+	p.mu.Unlock()
+
+	candidates := make([]*account, 0, len(p.accounts))
+	for i := range p.accounts {
+		acc := p.accounts[(start+i)%len(p.accounts)]
+		if acc.senderIDs == nil || acc.senderIDs.Allowed(from) {
+			candidates = append(candidates, acc)
+		}
+	}
+
+	switch p.strategy {
+	case SenderAffinity:
+		sort.SliceStable(candidates, func(i, k int) bool {
+			return candidates[i].senderIDs != nil && candidates[k].senderIDs == nil
+		})
+	case HighestCredits:
+		p.refreshCredits(ctx, candidates)
+
+		p.mu.Lock()
+		sort.SliceStable(candidates, func(i, k int) bool {
+			ci, ck := candidates[i], candidates[k]
+			if ci.hasCredits != ck.hasCredits {
+				return ci.hasCredits
+			}
+			return ci.credits > ck.credits
+		})
+		p.mu.Unlock()
+	}
+
+	return candidates
+}
+
+// refreshCredits concurrently checks the balance of every account whose balance is older than CreditsMaxAge
+// A balance is checked by one send at a time, other sends use the cached balance until the check finishes.
+// Accounts whose balance can't be checked are ordered last until it is checked again
+func (p *Pool) refreshCredits(ctx context.Context, accounts []*account) {
+	var wg sync.WaitGroup
+	for _, acc := range accounts {
+		p.mu.Lock()
+		refresh := !acc.refreshing && p.now().Sub(acc.creditsAt) >= p.creditsMaxAge
+		if refresh {
+			acc.refreshing = true
+		}
+		p.mu.Unlock()
+
+		if !refresh {
+			continue
+		}
+
+		wg.Add(1)
+		go func(acc *account) {
+			defer wg.Done()
+
+			_, _ = p.checkCredits(ctx, acc)
+
+			p.mu.Lock()
+			acc.refreshing = false
+			p.mu.Unlock()
+		}(acc)
+	}
+	wg.Wait()
+}
+
+// checkCredits checks and caches the balance of acc
+func (p *Pool) checkCredits(ctx context.Context, acc *account) (credits float64, err error) {
+	scr, _, err := acc.client.CheckSMSCredits(ctx)
+	if err == nil {
+		credits, err = scr.Credits()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	acc.credits, acc.hasCredits, acc.creditsAt = credits, err == nil, p.now()
+
+	return
+}
+
+// setCredits caches the balance of acc
+func (p *Pool) setCredits(acc *account, credits float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	acc.credits, acc.hasCredits, acc.creditsAt = credits, true, p.now()
+}
+
+// spend lowers the cached balance of acc by the credits used by a send
+func (p *Pool) spend(acc *account, creditsUsed int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if acc.hasCredits {
+		acc.credits -= float64(creditsUsed)
+	}
+}
+
+// CreditsError is returned by Pool.CheckSMSCredits when the balance of some accounts couldn't be checked
+type CreditsError struct {
+	// Accounts maps the name of every account which failed to its error
+	Accounts map[string]error
+}
+
+func (e *CreditsError) Error() string {
+	names := make([]string, 0, len(e.Accounts))
+	for name := range e.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("account %q: %v", name, e.Accounts[name])
+	}
+	return "pool: checking sms credits: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether the error of any account matches target
+func (e *CreditsError) Is(target error) bool {
+	for _, err := range e.Accounts {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSMSCredits implements jusibe.Client
+// It returns the total balance of the accounts which answered and a nil *http.Response. When some accounts fail,
+// the total is returned with a *CreditsError holding their errors. The total is nil when every account fails
+func (p *Pool) CheckSMSCredits(ctx context.Context) (scr *jusibe.SMSCreditsResponse, res *http.Response, err error) {
+	var total float64
+	failed := map[string]error{}
+	for _, acc := range p.accounts {
+		credits, checkErr := p.checkCredits(ctx, acc)
+		if checkErr != nil {
+			failed[acc.name] = checkErr
+			continue
+		}
+		total += credits
+	}
+
+	if len(failed) < len(p.accounts) {
+		scr = &jusibe.SMSCreditsResponse{SMSCredits: strconv.FormatFloat(total, 'f', -1, 64)}
+	}
+	if len(failed) > 0 {
+		err = &CreditsError{Accounts: failed}
+	}
+
+	return
+}
+
+// CheckSMSDeliveryStatus implements jusibe.Client
+// It asks the account which sent messageID, or every account in turn when the pool doesn't remember it
+func (p *Pool) CheckSMSDeliveryStatus(ctx context.Context, messageID string) (sdr *jusibe.SMSDeliveryResponse, res *http.Response, err error) {
+	err = p.lookup(messageID, func(acc *account) (checkErr error) {
+		sdr, res, checkErr = acc.client.CheckSMSDeliveryStatus(ctx, messageID)
+		return
+	})
+	return
+}
+
+// CheckBulkSMSStatus implements jusibe.Client
+// It asks the account which sent messageID, or every account in turn when the pool doesn't remember it
+func (p *Pool) CheckBulkSMSStatus(ctx context.Context, messageID string) (bsr *jusibe.BulkSMSStatusResponse, res *http.Response, err error) {
+	err = p.lookup(messageID, func(acc *account) (checkErr error) {
+		bsr, res, checkErr = acc.client.CheckBulkSMSStatus(ctx, messageID)
+		return
+	})
+	return
+}
+
+// lookup calls check with the account owning messageID
+// Unknown message ids are checked with every account until one doesn't fail with jusibe.ErrNotFound,
+// which is then remembered as the owner
+func (p *Pool) lookup(messageID string, check func(acc *account) error) (err error) {
+	if acc := p.owner(messageID); acc != nil {
+		return check(acc)
+	}
+
+	for _, acc := range p.accounts {
+		err = check(acc)
+		if err == nil {
+			p.remember(messageID, acc)
+		}
+		if !errors.Is(err, jusibe.ErrNotFound) {
+			return
+		}
+	}
+
+	return
+}
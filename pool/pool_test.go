@@ -0,0 +1,330 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newServers starts a fake Jusibe server per credits balance
+// Each server is warmed up with a different number of messages, so that message ids are unique across servers
+func newServers(t *testing.T, credits ...int) (servers []*jusibetest.Server) {
+	for i, c := range credits {
+		srv := jusibetest.NewServer("some_public_key", "some_access_token", i*10)
+
+		j, err := jusibe.New(srv.Config())
+		assert.NoError(t, err)
+		for k := 0; k < i*10; k++ {
+			_, _, err = j.SendSMS(context.Background(), "09001000101", "warmup", "a")
+			assert.NoError(t, err)
+		}
+		srv.SetCredits(c)
+
+		servers = append(servers, srv)
+	}
+	return
+}
+
+func closeServers(servers []*jusibetest.Server) {
+	for _, srv := range servers {
+		srv.Close()
+	}
+}
+
+// creditsGate counts balance checks and blocks them until release is closed
+type creditsGate struct {
+	checks   int32
+	checking chan struct{}
+	release  chan struct{}
+}
+
+func (g *creditsGate) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/get_credits") {
+		atomic.AddInt32(&g.checks, 1)
+		g.checking <- struct{}{}
+		<-g.release
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestPool(t *testing.T) {
+	t.Run("New should validate accounts and options", func(t *testing.T) {
+		cfg := &jusibe.Config{PublicKey: "some_public_key", AccessToken: "some_access_token"}
+
+		_, err := New(nil, nil)
+		assert.Error(t, err)
+		_, err = New([]Account{{Config: cfg}}, nil)
+		assert.Error(t, err)
+		_, err = New([]Account{{Name: "a", Config: cfg}, {Name: "a", Config: cfg}}, nil)
+		assert.Error(t, err)
+		_, err = New([]Account{{Name: "a"}}, nil)
+		assert.Error(t, err)
+		_, err = New([]Account{{Name: "a", Config: &jusibe.Config{}}}, nil)
+		assert.Error(t, err)
+		_, err = New([]Account{{Name: "a", Config: cfg}}, &Options{Strategy: Strategy(42)})
+		assert.Error(t, err)
+
+		p, err := New([]Account{{Name: "a", Config: cfg}}, nil)
+		assert.NoError(t, err)
+		client, ok := p.Client("a")
+		assert.True(t, ok)
+		assert.NotNil(t, client)
+	})
+
+	t.Run("RoundRobin should rotate, fail over and route status checks", func(t *testing.T) {
+		servers := newServers(t, 10, 10)
+		defer closeServers(servers)
+
+		unauthorized := servers[0].Config()
+		unauthorized.AccessToken = "wrong_access_token"
+
+		p, err := New([]Account{
+			{Name: "a", Config: servers[0].Config()},
+			{Name: "b", Config: servers[1].Config()},
+			{Name: "unauthorized", Config: unauthorized},
+		}, nil)
+		assert.NoError(t, err)
+
+		ctx := context.Background()
+		var owners []string
+		for i := 0; i < 3; i++ {
+			ssr, _, err := p.SendSMS(ctx, "09001000101", "test_user", "Hello World!")
+			assert.NoError(t, err)
+
+			owner, ok := p.Owner(ssr.MessageID)
+			assert.True(t, ok)
+			owners = append(owners, owner)
+		}
+		assert.Equal(t, []string{"a", "b", "a"}, owners, "the unauthorized account should fail over to the next one")
+
+		servers[0].SetCredits(0)
+		bsr, _, err := p.SendBulkSMS(ctx, "09001000101,09001000102", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		owner, _ := p.Owner(bsr.MessageID)
+		assert.Equal(t, "b", owner, "the account without credits should fail over to the next one")
+
+		ssr, _, err := p.SendSMS(ctx, "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		owner, _ = p.Owner(ssr.MessageID)
+		assert.Equal(t, "b", owner)
+
+		aRequests, bRequests := servers[0].Requests(), servers[1].Requests()
+		_, _, err = p.CheckSMSDeliveryStatus(ctx, ssr.MessageID)
+		assert.NoError(t, err)
+		_, _, err = p.CheckBulkSMSStatus(ctx, bsr.MessageID)
+		assert.NoError(t, err)
+		assert.Equal(t, aRequests, servers[0].Requests(), "status checks should only be sent to the owner")
+		assert.Equal(t, bRequests+2, servers[1].Requests())
+	})
+
+	t.Run("lookup should find and remember the owner of unknown message ids", func(t *testing.T) {
+		servers := newServers(t, 10, 10)
+		defer closeServers(servers)
+
+		j, err := jusibe.New(servers[1].Config())
+		assert.NoError(t, err)
+		ssr, _, err := j.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+
+		p, err := New([]Account{{Name: "a", Config: servers[0].Config()}, {Name: "b", Config: servers[1].Config()}}, nil)
+		assert.NoError(t, err)
+
+		_, ok := p.Owner(ssr.MessageID)
+		assert.False(t, ok)
+
+		sdr, _, err := p.CheckSMSDeliveryStatus(context.Background(), ssr.MessageID)
+		assert.NoError(t, err)
+		assert.Equal(t, ssr.MessageID, sdr.MessageID)
+
+		owner, _ := p.Owner(ssr.MessageID)
+		assert.Equal(t, "b", owner)
+
+		assert.Error(t, p.Remember(ssr.MessageID, "unknown"))
+		assert.NoError(t, p.Remember(ssr.MessageID, "a"))
+		owner, _ = p.Owner(ssr.MessageID)
+		assert.Equal(t, "a", owner)
+	})
+
+	t.Run("SenderAffinity should prefer accounts holding the sender id", func(t *testing.T) {
+		servers := newServers(t, 10, 10, 10)
+		defer closeServers(servers)
+
+		shopIDs, err := jusibe.NewSenderIDRegistry("MyShop")
+		assert.NoError(t, err)
+		alertIDs, err := jusibe.NewSenderIDRegistry("Alerts")
+		assert.NoError(t, err)
+
+		shop, alerts := servers[0].Config(), servers[2].Config()
+		shop.SenderIDs, alerts.SenderIDs = shopIDs, alertIDs
+
+		p, err := New([]Account{
+			{Name: "shop", Config: shop},
+			{Name: "any", Config: servers[1].Config()},
+			{Name: "alerts", Config: alerts},
+		}, &Options{Strategy: SenderAffinity})
+		assert.NoError(t, err)
+
+		for _, tc := range []struct{ from, owner string }{
+			{"MyShop", "shop"}, {"myshop", "shop"}, {"Alerts", "alerts"}, {"Other", "any"}, {"MyShop", "shop"}, {"Other", "any"},
+		} {
+			ssr, _, err := p.SendSMS(context.Background(), "09001000101", tc.from, "Hello World!")
+			assert.NoError(t, err)
+			owner, _ := p.Owner(ssr.MessageID)
+			assert.Equal(t, tc.owner, owner, tc.from)
+		}
+
+		servers[0].SetCredits(0)
+		ssr, _, err := p.SendSMS(context.Background(), "09001000101", "MyShop", "Hello World!")
+		assert.NoError(t, err)
+		owner, _ := p.Owner(ssr.MessageID)
+		assert.Equal(t, "any", owner, "accounts without a registry should be the fallback")
+	})
+
+	t.Run("Send should fail with ErrNoAccount when every account refuses the sender id", func(t *testing.T) {
+		ids, err := jusibe.NewSenderIDRegistry("MyShop")
+		assert.NoError(t, err)
+
+		p, err := New([]Account{{Name: "shop", Config: &jusibe.Config{PublicKey: "some_public_key", AccessToken: "some_access_token", SenderIDs: ids}}}, nil)
+		assert.NoError(t, err)
+
+		_, _, err = p.SendSMS(context.Background(), "09001000101", "Other", "Hello World!")
+		assert.True(t, errors.Is(err, ErrNoAccount))
+	})
+
+	t.Run("HighestCredits should prefer the account with the most credits", func(t *testing.T) {
+		servers := newServers(t, 5, 50)
+		defer closeServers(servers)
+
+		p, err := New([]Account{{Name: "a", Config: servers[0].Config()}, {Name: "b", Config: servers[1].Config()}}, &Options{Strategy: HighestCredits})
+		assert.NoError(t, err)
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		p.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			ssr, _, err := p.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+			assert.NoError(t, err)
+			owner, _ := p.Owner(ssr.MessageID)
+			assert.Equal(t, "b", owner)
+		}
+
+		servers[1].SetCredits(1)
+		now = now.Add(defaultCreditsMaxAge)
+
+		ssr, _, err := p.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		owner, _ := p.Owner(ssr.MessageID)
+		assert.Equal(t, "a", owner, "balances should be checked again after CreditsMaxAge")
+
+		scr, _, err := p.CheckSMSCredits(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "5", scr.SMSCredits)
+	})
+
+	t.Run("HighestCredits should estimate the credits used by bulk sends", func(t *testing.T) {
+		servers := newServers(t, 5, 6)
+		defer closeServers(servers)
+
+		p, err := New([]Account{{Name: "a", Config: servers[0].Config()}, {Name: "b", Config: servers[1].Config()}}, &Options{Strategy: HighestCredits})
+		assert.NoError(t, err)
+
+		var owners []string
+		for i := 0; i < 2; i++ {
+			bsr, _, err := p.SendBulkSMS(context.Background(), "09001000101, 09001000102", "test_user", "Hello World!")
+			assert.NoError(t, err)
+			owner, _ := p.Owner(bsr.MessageID)
+			owners = append(owners, owner)
+		}
+		assert.Equal(t, []string{"b", "a"}, owners, "b should be estimated at 4 credits after the first send")
+
+		requests := servers[0].Requests() + servers[1].Requests()
+		_, _, err = p.SendBulk(context.Background(), &jusibe.BulkSMSRequest{To: []string{"09001000101"}, From: "test_user", Message: "Hello World!"})
+		assert.NoError(t, err)
+		assert.Equal(t, requests+1, servers[0].Requests()+servers[1].Requests(), "balances should not be checked again")
+	})
+
+	t.Run("HighestCredits should check each balance once at a time", func(t *testing.T) {
+		servers := newServers(t, 5, 50)
+		defer closeServers(servers)
+
+		gate := &creditsGate{checking: make(chan struct{}, 2), release: make(chan struct{})}
+		p, err := New([]Account{
+			{Name: "a", Config: servers[0].Config(), HTTPClient: &http.Client{Transport: gate}},
+			{Name: "b", Config: servers[1].Config(), HTTPClient: &http.Client{Transport: gate}},
+		}, &Options{Strategy: HighestCredits})
+		assert.NoError(t, err)
+
+		first := make(chan error)
+		go func() {
+			_, _, err := p.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+			first <- err
+		}()
+		<-gate.checking
+		<-gate.checking
+
+		_, _, err = p.SendSMS(context.Background(), "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err, "sends should not wait for balances checked by another send")
+
+		close(gate.release)
+		assert.NoError(t, <-first)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&gate.checks))
+	})
+
+	t.Run("CheckSMSCredits should sum the accounts which answered", func(t *testing.T) {
+		servers := newServers(t, 5, 7)
+		defer closeServers(servers)
+
+		unauthorized := servers[0].Config()
+		unauthorized.AccessToken = "wrong_access_token"
+
+		p, err := New([]Account{
+			{Name: "a", Config: servers[0].Config()},
+			{Name: "unauthorized", Config: unauthorized},
+			{Name: "b", Config: servers[1].Config()},
+		}, nil)
+		assert.NoError(t, err)
+
+		scr, _, err := p.CheckSMSCredits(context.Background())
+		if assert.NotNil(t, scr) {
+			assert.Equal(t, "12", scr.SMSCredits)
+		}
+
+		var creditsErr *CreditsError
+		if assert.True(t, errors.As(err, &creditsErr)) {
+			assert.Len(t, creditsErr.Accounts, 1)
+			assert.True(t, errors.Is(creditsErr.Accounts["unauthorized"], jusibe.ErrUnauthorized))
+		}
+		assert.True(t, errors.Is(err, jusibe.ErrUnauthorized))
+		assert.Contains(t, err.Error(), `account "unauthorized"`)
+
+		p, err = New([]Account{{Name: "unauthorized", Config: unauthorized}}, nil)
+		assert.NoError(t, err)
+		scr, _, err = p.CheckSMSCredits(context.Background())
+		assert.Nil(t, scr)
+		assert.True(t, errors.Is(err, jusibe.ErrUnauthorized))
+	})
+
+	t.Run("remember should forget the oldest message ids", func(t *testing.T) {
+		p, err := New([]Account{{Name: "a", Config: &jusibe.Config{PublicKey: "some_public_key", AccessToken: "some_access_token"}}}, &Options{MaxMessageIDs: 2})
+		assert.NoError(t, err)
+
+		for _, id := range []string{"1", "2", "2", "3"} {
+			assert.NoError(t, p.Remember(id, "a"))
+		}
+
+		_, ok := p.Owner("1")
+		assert.False(t, ok)
+		_, ok = p.Owner("2")
+		assert.True(t, ok)
+		_, ok = p.Owner("3")
+		assert.True(t, ok)
+	})
+}
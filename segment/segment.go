@@ -0,0 +1,143 @@
+/*
+Package segment estimates how many SMS parts (segments) a message is split into, and the credits it costs.
+
+Messages made up only of GSM 03.38 characters are sent with the 7-bit GSM encoding, which fits 160
+characters in a single SMS. Characters from the GSM extension table, such as { } [ ] ~ | ^ \ and €, take
+two septets. Any other character, e.g an emoji or an accented letter missing from the GSM alphabet,
+switches the whole message to UCS-2, which fits 70 UTF-16 code units in a single SMS.
+
+Long messages are concatenated, and every part then loses room to the User Data Header: 153 septets
+per part with GSM 7-bit, or 67 code units per part with UCS-2.
+
+Example Usage:
+
+	e := segment.Calculate("Hello World 👋")
+	fmt.Println(e.Encoding, e.Segments, e.Credits(100)) // UCS-2 1 100
+*/
+package segment
+
+// Encoding is the character encoding of an SMS
+type Encoding string
+
+const (
+	// GSM7 is the GSM 03.38 7-bit default alphabet
+	GSM7 Encoding = "GSM-7"
+
+	// UCS2 is the UCS-2 (UTF-16) encoding, used when a message has characters outside the GSM alphabet
+	UCS2 Encoding = "UCS-2"
+)
+
+const (
+	// GSM7SingleLimit is the number of septets in a single GSM 7-bit SMS
+	GSM7SingleLimit = 160
+
+	// GSM7MultiLimit is the number of septets in each part of a concatenated GSM 7-bit SMS
+	GSM7MultiLimit = 153
+
+	// UCS2SingleLimit is the number of UTF-16 code units in a single UCS-2 SMS
+	UCS2SingleLimit = 70
+
+	// UCS2MultiLimit is the number of UTF-16 code units in each part of a concatenated UCS-2 SMS
+	UCS2MultiLimit = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet
+var gsm7Basic = toSet("@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà")
+
+// gsm7Extension is the GSM 03.38 extension table, whose characters are sent as an escape and a septet
+var gsm7Extension = toSet("\f^{}\\[~]|€")
+
+func toSet(chars string) map[rune]bool {
+	set := map[rune]bool{}
+	for _, r := range chars {
+		set[r] = true
+	}
+	return set
+}
+
+// Estimate is the result of Calculate
+type Estimate struct {
+	// Encoding is the encoding the message is sent with
+	Encoding Encoding
+
+	// Characters is the number of characters (runes) in the message
+	Characters int
+
+	// Units is the number of encoded units in the message: septets with GSM7, UTF-16 code units with UCS2
+	Units int
+
+	// Segments is the number of SMS parts the message is split into. It is 0 for an empty message
+	Segments int
+
+	// UnitsPerSegment is the capacity of each part, which is smaller for concatenated messages
+	UnitsPerSegment int
+
+	// Remaining is the number of units left in the last part before another part is needed
+	Remaining int
+}
+
+// Credits returns the estimated credits used to send the message to the specified number of recipients
+// Jusibe charges a credit per part per recipient
+func (e Estimate) Credits(recipients int) int {
+	return e.Segments * recipients
+}
+
+// Calculate estimates the encoding and number of parts of message
+func Calculate(message string) (e Estimate) {
+	e.Encoding = GSM7
+	for _, r := range message {
+		e.Characters++
+		if !gsm7Basic[r] && !gsm7Extension[r] {
+			e.Encoding = UCS2
+		}
+	}
+
+	costs := make([]int, 0, e.Characters)
+	for _, r := range message {
+		costs = append(costs, unitCost(e.Encoding, r))
+		e.Units += costs[len(costs)-1]
+	}
+
+	singleLimit, multiLimit := GSM7SingleLimit, GSM7MultiLimit
+	if e.Encoding == UCS2 {
+		singleLimit, multiLimit = UCS2SingleLimit, UCS2MultiLimit
+	}
+
+	switch {
+	case e.Units == 0:
+		e.UnitsPerSegment, e.Remaining = singleLimit, singleLimit
+	case e.Units <= singleLimit:
+		e.Segments, e.UnitsPerSegment, e.Remaining = 1, singleLimit, singleLimit-e.Units
+	default:
+		// Escaped GSM characters and UTF-16 surrogate pairs are never split across parts
+		used := 0
+		e.Segments = 1
+		for _, cost := range costs {
+			if used+cost > multiLimit {
+				e.Segments++
+				used = 0
+			}
+			used += cost
+		}
+		e.UnitsPerSegment, e.Remaining = multiLimit, multiLimit-used
+	}
+
+	return
+}
+
+// unitCost returns the number of units r takes with encoding
+func unitCost(encoding Encoding, r rune) int {
+	if encoding == GSM7 {
+		if gsm7Extension[r] {
+			return 2
+		}
+		return 1
+	}
+
+	// Characters outside the Basic Multilingual Plane are encoded as a UTF-16 surrogate pair
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
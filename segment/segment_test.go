@@ -0,0 +1,68 @@
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculate(t *testing.T) {
+	t.Run("empty message should have no segments", func(t *testing.T) {
+		e := Calculate("")
+		assert.Equal(t, GSM7, e.Encoding)
+		assert.Equal(t, 0, e.Segments)
+		assert.Equal(t, 0, e.Credits(10))
+	})
+
+	t.Run("GSM 7-bit messages should fit 160 septets in one segment", func(t *testing.T) {
+		e := Calculate(strings.Repeat("a", 160))
+		assert.Equal(t, Estimate{Encoding: GSM7, Characters: 160, Units: 160, Segments: 1, UnitsPerSegment: 160, Remaining: 0}, e)
+
+		e = Calculate(strings.Repeat("a", 161))
+		assert.Equal(t, 2, e.Segments)
+		assert.Equal(t, GSM7MultiLimit, e.UnitsPerSegment)
+		assert.Equal(t, 153-8, e.Remaining)
+
+		e = Calculate(strings.Repeat("a", 306))
+		assert.Equal(t, 2, e.Segments)
+
+		e = Calculate(strings.Repeat("a", 307))
+		assert.Equal(t, 3, e.Segments)
+		assert.Equal(t, 6, e.Credits(2))
+	})
+
+	t.Run("GSM extension characters should take two septets", func(t *testing.T) {
+		e := Calculate("Price: 10€ {promo}")
+		assert.Equal(t, GSM7, e.Encoding)
+		assert.Equal(t, 18, e.Characters)
+		assert.Equal(t, 21, e.Units)
+
+		e = Calculate(strings.Repeat("a", 159) + "€")
+		assert.Equal(t, 2, e.Segments, "escape sequences which don't fit should spill over")
+
+		e = Calculate(strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10))
+		assert.Equal(t, 2, e.Segments)
+		assert.Equal(t, 153-12, e.Remaining, "escape sequences should not be split across segments")
+	})
+
+	t.Run("non GSM characters should switch to UCS-2", func(t *testing.T) {
+		e := Calculate("Hello World 👋")
+		assert.Equal(t, UCS2, e.Encoding)
+		assert.Equal(t, 13, e.Characters)
+		assert.Equal(t, 14, e.Units)
+		assert.Equal(t, 1, e.Segments)
+		assert.Equal(t, 56, e.Remaining)
+
+		e = Calculate(strings.Repeat("ţ", 70))
+		assert.Equal(t, 1, e.Segments)
+
+		e = Calculate(strings.Repeat("ţ", 71))
+		assert.Equal(t, 2, e.Segments)
+		assert.Equal(t, UCS2MultiLimit, e.UnitsPerSegment)
+
+		e = Calculate(strings.Repeat("ţ", 66) + "👋" + strings.Repeat("ţ", 10))
+		assert.Equal(t, 2, e.Segments)
+		assert.Equal(t, 67-12, e.Remaining, "surrogate pairs should not be split across segments")
+	})
+}
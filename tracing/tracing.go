@@ -0,0 +1,280 @@
+/*
+Package tracing implements jusibe.Tracer with W3C Trace Context propagation.
+
+Spans are started from the span in the caller's context, or from a remote parent extracted from an incoming
+traceparent header, and are handed to an Exporter when they end. Every outbound Jusibe request carries a
+traceparent header, so the calls can be correlated with the rest of a distributed trace.
+
+An InMemoryExporter keeps finished spans in memory, which is mostly useful in tests.
+
+Example Usage:
+
+	exporter := tracing.NewInMemoryExporter()
+
+	j, err := jusibe.New(&jusibe.Config{
+		PublicKey:   os.Getenv("JUSIBE_PUBLIC_KEY"),
+		AccessToken: os.Getenv("JUSIBE_ACCESS_TOKEN"),
+		Tracer:      tracing.NewTracer(exporter),
+	})
+
+	_, _, err = j.SendSMS(ctx, "08031234567", "Jusibe", "Hello World!")
+
+	for _, span := range exporter.Spans() {
+		fmt.Println(span.Name, span.TraceID, span.Attributes["http.status_code"])
+	}
+*/
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+)
+
+// TraceParentHeader is the W3C Trace Context header written on outbound requests
+const TraceParentHeader = "traceparent"
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	// TraceID is 32 lowercase hex characters
+	TraceID string
+
+	// SpanID is 16 lowercase hex characters
+	SpanID string
+
+	Sampled bool
+}
+
+// IsValid reports whether sc has a trace id and a span id
+func (sc SpanContext) IsValid() bool {
+	return isHex(sc.TraceID, 32) && isHex(sc.SpanID, 16)
+}
+
+// SpanData is a finished span
+type SpanData struct {
+	Name string
+	SpanContext
+
+	// ParentSpanID is empty for root spans
+	ParentSpanID string
+
+	Attributes map[string]interface{}
+
+	// Err is the last error recorded on the span
+	Err error
+
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Exporter receives spans when they end
+// It is called from the goroutines making calls, so it must be safe for concurrent use
+type Exporter interface {
+	ExportSpan(s SpanData)
+}
+
+// Tracer implements jusibe.Tracer
+// Create a Tracer with NewTracer
+type Tracer struct {
+	exporter Exporter
+}
+
+var _ jusibe.Tracer = (*Tracer)(nil)
+
+// NewTracer creates a Tracer exporting finished spans to exporter
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+type spanContextKey struct{}
+
+// Start implements jusibe.Tracer
+// The span is a child of the span in ctx, or of the remote parent added by Extract, if any
+func (t *Tracer) Start(ctx context.Context, spanName string) (context.Context, jusibe.Span) {
+	s := &Span{
+		exporter: t.exporter,
+		data: SpanData{
+			Name:        spanName,
+			SpanContext: SpanContext{TraceID: newID(16), SpanID: newID(8), Sampled: true},
+			Attributes:  map[string]interface{}{},
+			StartTime:   time.Now(),
+		},
+	}
+
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		s.data.TraceID = parent.TraceID
+		s.data.Sampled = parent.Sampled
+		s.data.ParentSpanID = parent.SpanID
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, s.data.SpanContext), s
+}
+
+// Inject implements jusibe.Tracer
+// It writes a traceparent header for the span in ctx, if any
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		header.Set(TraceParentHeader, FormatTraceParent(sc))
+	}
+}
+
+// Extract returns a copy of ctx holding the remote span context of a valid traceparent header, if any
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, ok := ParseTraceParent(header.Get(TraceParentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context in ctx, if any
+func SpanContextFromContext(ctx context.Context) (sc SpanContext) {
+	sc, _ = ctx.Value(spanContextKey{}).(SpanContext)
+	return
+}
+
+// FormatTraceParent formats sc as a version 00 traceparent header value
+func FormatTraceParent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID + "-" + sc.SpanID + "-" + flags
+}
+
+// ParseTraceParent parses a traceparent header value
+// It reports false when the value is malformed or has an all-zero trace id or span id
+func ParseTraceParent(value string) (sc SpanContext, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) || !isHex(parts[3], 2) {
+		return
+	}
+
+	sc = SpanContext{TraceID: parts[1], SpanID: parts[2]}
+	if !sc.IsValid() || strings.Trim(sc.TraceID, "0") == "" || strings.Trim(sc.SpanID, "0") == "" {
+		return SpanContext{}, false
+	}
+
+	flags, _ := hex.DecodeString(parts[3])
+	sc.Sampled = flags[0]&1 == 1
+
+	return sc, true
+}
+
+// Span implements jusibe.Span
+type Span struct {
+	exporter Exporter
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context of s
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// SetAttribute implements jusibe.Span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.data.Attributes[key] = value
+	}
+}
+
+// RecordError implements jusibe.Span
+func (s *Span) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended && err != nil {
+		s.data.Err = err
+	}
+}
+
+// End implements jusibe.Span
+// It exports the span once, later calls do nothing
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.exporter != nil && data.Sampled {
+		s.exporter.ExportSpan(data)
+	}
+}
+
+// InMemoryExporter keeps finished spans in memory
+// Create an InMemoryExporter with NewInMemoryExporter
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+var _ Exporter = (*InMemoryExporter)(nil)
+
+// NewInMemoryExporter creates an empty InMemoryExporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan implements Exporter
+func (e *InMemoryExporter) ExportSpan(s SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, s)
+}
+
+// Spans returns the exported spans in the order they ended
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset removes every exported span
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
+
+// newID returns n random bytes as lowercase hex
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("tracing: reading random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// isHex reports whether s is n lowercase hex characters
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
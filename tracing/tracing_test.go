@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/azeezolaniran2016/jusibe-go/jusibe"
+	"github.com/azeezolaniran2016/jusibe-go/jusibetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// headerRecorder records the traceparent header of every request before sending it with http.DefaultTransport
+type headerRecorder struct {
+	mu           sync.Mutex
+	traceParents []string
+}
+
+func (r *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.traceParents = append(r.traceParents, req.Header.Get(TraceParentHeader))
+	r.mu.Unlock()
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("Tracer should record a span per call and propagate it", func(t *testing.T) {
+		srv := jusibetest.NewServer("some_public_key", "some_access_token", 10)
+		defer srv.Close()
+
+		exporter := NewInMemoryExporter()
+		recorder := &headerRecorder{}
+		cfg := srv.Config()
+		cfg.Tracer = NewTracer(exporter)
+
+		j, err := jusibe.NewWithHTTPClient(cfg, &http.Client{Transport: recorder})
+		assert.NoError(t, err)
+
+		parent := SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}
+		ctx := Extract(context.Background(), http.Header{"Traceparent": {FormatTraceParent(parent)}})
+
+		ssr, _, err := j.SendSMS(ctx, "09001000101", "test_user", "Hello World!")
+		assert.NoError(t, err)
+		_, _, err = j.CheckSMSDeliveryStatus(context.Background(), "unknown")
+		assert.True(t, errors.Is(err, jusibe.ErrNotFound))
+
+		spans := exporter.Spans()
+		if !assert.Len(t, spans, 2) || !assert.Len(t, recorder.traceParents, 2) {
+			return
+		}
+
+		send := spans[0]
+		assert.Equal(t, "jusibe.SendSMS", send.Name)
+		assert.Equal(t, parent.TraceID, send.TraceID)
+		assert.Equal(t, parent.SpanID, send.ParentSpanID)
+		assert.Equal(t, "SendSMS", send.Attributes["jusibe.operation"])
+		assert.Equal(t, 200, send.Attributes["http.status_code"])
+		assert.Equal(t, ssr.MessageID, send.Attributes["jusibe.message_id"])
+		assert.Equal(t, 1, send.Attributes["jusibe.sms_credits_used"])
+		assert.NoError(t, send.Err)
+		assert.False(t, send.EndTime.Before(send.StartTime))
+		assert.Equal(t, FormatTraceParent(send.SpanContext), recorder.traceParents[0])
+
+		check := spans[1]
+		assert.Equal(t, "jusibe.CheckSMSDeliveryStatus", check.Name)
+		assert.NotEqual(t, parent.TraceID, check.TraceID)
+		assert.Empty(t, check.ParentSpanID)
+		assert.Equal(t, 404, check.Attributes["http.status_code"])
+		assert.Equal(t, "unknown", check.Attributes["jusibe.message_id"])
+		assert.True(t, errors.Is(check.Err, jusibe.ErrNotFound))
+		assert.Equal(t, FormatTraceParent(check.SpanContext), recorder.traceParents[1])
+
+		exporter.Reset()
+		assert.Empty(t, exporter.Spans())
+	})
+
+	t.Run("Tracer should not export unsampled spans", func(t *testing.T) {
+		exporter := NewInMemoryExporter()
+		tracer := NewTracer(exporter)
+
+		ctx := Extract(context.Background(), http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}})
+		ctx, span := tracer.Start(ctx, "unsampled")
+		span.End()
+
+		header := http.Header{}
+		tracer.Inject(ctx, header)
+
+		assert.Empty(t, exporter.Spans())
+		assert.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-00$`, header.Get(TraceParentHeader))
+	})
+
+	t.Run("ParseTraceParent should reject malformed values", func(t *testing.T) {
+		for _, value := range []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		} {
+			_, ok := ParseTraceParent(value)
+			assert.False(t, ok, value)
+		}
+
+		sc, ok := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03-future")
+		assert.True(t, ok)
+		assert.True(t, sc.Sampled)
+	})
+}